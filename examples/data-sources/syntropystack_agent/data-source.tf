@@ -1,3 +1,17 @@
 data "syntropystack_agent" "agent_1" {
   name = "syntropy-agent-prod"
-}
\ No newline at end of file
+}
+
+data "syntropystack_agent" "agent_2" {
+  name  = "web-"
+  match = "prefix"
+}
+
+data "syntropystack_agent" "agent_3" {
+  public_ipv4 = "203.0.113.10"
+}
+data "syntropystack_agent" "agent_4" {
+  name                = "syntropy-agent-prod"
+  include_services    = true
+  include_connections = true
+}
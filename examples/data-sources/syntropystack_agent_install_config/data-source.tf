@@ -0,0 +1,12 @@
+data "syntropystack_agent_install_config" "edge" {
+  token        = "<AGENT_TOKEN>"
+  name         = "edge-eu-1"
+  tags         = ["edge", "eu"]
+  network_mode = "docker"
+}
+
+resource "aws_instance" "edge" {
+  ami           = "<AMI_ID>"
+  instance_type = "t3.micro"
+  user_data     = data.syntropystack_agent_install_config.edge.cloud_init
+}
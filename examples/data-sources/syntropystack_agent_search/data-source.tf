@@ -12,4 +12,64 @@ data "syntropystack_agent_search" "agent_2" {
     type   = ["LINUX"]
     status = ["CONNECTED"]
   }
-}
\ No newline at end of file
+}
+
+# prod AND eu AND NOT canary
+data "syntropystack_agent_search" "prod_eu" {
+  filter = {
+    tags_all  = ["prod", "eu"]
+    tags_none = ["canary"]
+  }
+}
+
+data "syntropystack_agent_search" "prod_eu_healthy" {
+  filter = {
+    tags_all    = ["prod", "eu"]
+    online_only = true
+    stale_after = "1h"
+  }
+}
+
+resource "syntropystack_network_connection_mesh" "prod_eu" {
+  agent_ids = data.syntropystack_agent_search.prod_eu_healthy.ids
+}
+
+output "prod_eu_offline" {
+  value = data.syntropystack_agent_search.prod_eu_healthy.offline_ids
+}
+
+data "syntropystack_agent_search" "edge_eu_office" {
+  filter = {
+    name_regex      = "^edge-eu-[0-9]+$"
+    public_ip_cidrs = ["203.0.113.0/24"]
+  }
+}
+
+data "syntropystack_agent_search" "changed_last_day" {
+  filter = {
+    modified_at_from = "-24h"
+  }
+}
+
+data "syntropystack_agent_search" "stale" {
+  filter = {
+    modified_at_to = "7d ago"
+  }
+}
+
+data "syntropystack_agent_search" "latest" {
+  take = 10
+  order = {
+    field     = "modified_at"
+    direction = "desc"
+  }
+}
+
+data "syntropystack_agent_search" "bootstrapped" {
+  wait_for_count  = 3
+  wait_for_online = true
+  wait_timeout    = "15m"
+  filter = {
+    tags_all = ["prod", "eu"]
+  }
+}
@@ -0,0 +1,14 @@
+resource "syntropystack_agent_fleet" "edge" {
+  agents = {
+    "edge-eu-1" = {
+      name  = "edge-eu-1"
+      token = "<AGENT_TOKEN>"
+      tags  = ["edge", "eu"]
+    }
+    "edge-us-1" = {
+      name  = "edge-us-1"
+      token = "<AGENT_TOKEN>"
+      tags  = ["edge", "us"]
+    }
+  }
+}
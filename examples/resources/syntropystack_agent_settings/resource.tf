@@ -0,0 +1,10 @@
+data "syntropystack_agent" "host" {
+  name = "linux-host-1"
+}
+
+resource "syntropystack_agent_settings" "host" {
+  agent_id           = data.syntropystack_agent.host.id
+  name               = "web-eu-1"
+  tags               = ["web", "eu"]
+  restore_on_destroy = true
+}
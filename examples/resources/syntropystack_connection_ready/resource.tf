@@ -0,0 +1,9 @@
+resource "syntropystack_network_connection" "p2p" {
+  agent_peer  = [1, 2]
+  sdn_enabled = true
+}
+
+resource "syntropystack_connection_ready" "p2p" {
+  connection_group_ids = [syntropystack_network_connection.p2p.id]
+  timeout              = "5m"
+}
@@ -1,4 +1,34 @@
 resource "syntropystack_network_connection" "p2p" {
   agent_peer  = [1, 2]
   sdn_enabled = true
-}
\ No newline at end of file
+}
+
+resource "syntropystack_network_connection" "directed" {
+  agent_1_id  = 1
+  agent_2_id  = 3
+  sdn_enabled = true
+}
+
+resource "syntropystack_network_connection" "web" {
+  agent_peer = [1, 4]
+  enable_services = {
+    name_regex = "^web-"
+    type       = "DOCKER"
+    agent_side = "agent_2"
+  }
+}
+
+resource "syntropystack_network_connection" "db" {
+  agent_peer = [1, 5]
+  wait_for_services = {
+    names   = ["postgres"]
+    timeout = "5m"
+  }
+  enable_services = {
+    name_regex = "^postgres$"
+  }
+}
+
+resource "syntropystack_network_connection" "by_name" {
+  agent_peer_names = ["web-eu-1", "db-eu-1"]
+}
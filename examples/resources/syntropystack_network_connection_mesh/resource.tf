@@ -8,4 +8,8 @@ data "syntropystack_agent_search" "results" {
 resource "syntropystack_network_connection_mesh" "test_connection_mesh" {
   agent_ids   = data.syntropystack_agent_search.results.agents.*.id
   sdn_enabled = true
-}
\ No newline at end of file
+}
+
+resource "syntropystack_network_connection_mesh" "by_name" {
+  agent_names = ["web-eu-1", "web-eu-2", "db-eu-1"]
+}
@@ -0,0 +1,21 @@
+resource "syntropystack_network_connections" "edges" {
+  connections = [
+    {
+      agent_1_id  = 1
+      agent_2_id  = 2
+      sdn_enabled = true
+    },
+    {
+      agent_1_id = 1
+      agent_2_id = 3
+    },
+    {
+      agent_1_id = 2
+      agent_2_id = 3
+    },
+  ]
+}
+
+output "edge_1_2" {
+  value = syntropystack_network_connections.edges.connection_group_ids["1:2"]
+}
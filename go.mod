@@ -15,6 +15,7 @@ require (
 
 require (
 	github.com/google/uuid v1.3.0
+	gopkg.in/yaml.v3 v3.0.1
 	github.com/hashicorp/terraform-plugin-log v0.7.0 // indirect
 )
 
@@ -2,25 +2,37 @@ package syntropy
 
 import (
 	"context"
+	"fmt"
 	"github.com/SyntropyNet/syntropy-sdk-go/syntropy"
+	"github.com/hashicorp/terraform-plugin-framework-validators/schemavalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strings"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces
 var _ tfsdk.DataSourceType = agentDataSourceType{}
 var _ tfsdk.DataSource = agentDataSource{}
 
+const (
+	agentNameMatchExact     = "exact"
+	agentNameMatchPrefix    = "prefix"
+	agentNameMatchSubstring = "substring"
+)
+
 type agentDataSourceType struct{}
 
 func (d agentDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
 	return tfsdk.Schema{
-		Description: "Datasource retrieves Syntropy agent data by agent name",
+		Description: "Datasource retrieves Syntropy agent data by agent name, ID, device ID or public IPv4 address",
 		Attributes: map[string]tfsdk.Attribute{
 			"id": {
-				Description: "Unique identifier for the agent",
+				Description: "Unique identifier for the agent. Can be used to look up agent instead of name",
 				Type:        types.Int64Type,
+				Optional:    true,
 				Computed:    true,
 				PlanModifiers: tfsdk.AttributePlanModifiers{
 					tfsdk.UseStateForUnknown(),
@@ -28,12 +40,41 @@ func (d agentDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Di
 			},
 			"name": {
 				Description: "Name of the agent as it appears in Platform UI",
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
+				Type:        types.StringType,
+				Validators: []tfsdk.AttributeValidator{
+					schemavalidator.ExactlyOneOf(
+						path.MatchRoot("id"),
+						path.MatchRoot("device_id"),
+						path.MatchRoot("public_ipv4"),
+					),
+				},
+			},
+			"match": {
+				Description: "How agent name is matched. Possible values: exact, prefix, substring. Defaults to exact",
+				Optional:    true,
 				Type:        types.StringType,
+				Validators: []tfsdk.AttributeValidator{
+					stringvalidator.OneOf(agentNameMatchExact, agentNameMatchPrefix, agentNameMatchSubstring),
+				},
 			},
+			"include_services": {
+				Description: "Should agent services be returned?",
+				Optional:    true,
+				Type:        types.BoolType,
+			},
+			"include_connections": {
+				Description: "Should agent connections be returned?",
+				Optional:    true,
+				Type:        types.BoolType,
+			},
+			"services":    agentServicesAttribute(),
+			"connections": agentConnectionsAttribute(),
 			"public_ipv4": {
-				Description: "IP address of the agent in IPv4 format",
+				Description: "IP address of the agent in IPv4 format. Can be used to look up agent instead of name",
 				Type:        types.StringType,
+				Optional:    true,
 				Computed:    true,
 			},
 			"status": {
@@ -62,8 +103,9 @@ func (d agentDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Di
 				Computed:    true,
 			},
 			"device_id": {
-				Description: "A unique agent identifier. Usually machine id or other unique UUID with a workspace id prefix (to scope this agent to workspace).",
+				Description: "A unique agent identifier. Usually machine id or other unique UUID with a workspace id prefix (to scope this agent to workspace). Can be used to look up agent instead of name",
 				Type:        types.StringType,
+				Optional:    true,
 				Computed:    true,
 			},
 			"is_virtual": {
@@ -129,7 +171,7 @@ type agentDataSource struct {
 }
 
 func (d agentDataSource) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
-	var data AgentData
+	var data AgentDataSource
 	ctx = d.provider.createAuthContext(ctx)
 	diags := req.Config.Get(ctx, &data)
 	resp.Diagnostics.Append(diags...)
@@ -138,42 +180,180 @@ func (d agentDataSource) Read(ctx context.Context, req tfsdk.ReadDataSourceReque
 		return
 	}
 
-	skip := int32(0)
-	take := int32(1)
-	aResp, _, err := d.provider.client.AgentsApi.V1NetworkAgentsSearch(ctx).V1NetworkAgentsSearchRequest(syntropy.V1NetworkAgentsSearchRequest{
-		Filter: nil,
-		Order:  nil,
-		Skip:   &skip,
-		Take:   &take,
-		Search: &data.Name,
-	}).Execute()
+	matches, lookup, err := d.findAgents(ctx, data)
 	if err != nil {
 		resp.Diagnostics.AddError("Error while getting Syntropy agent", err.Error())
 		return
 	}
 
-	for _, agent := range aResp.Data {
-		data = AgentData{
-			ID:              types.Int64{Value: int64(agent.AgentId)},
-			Name:            agent.AgentName,
-			PublicIPv4:      types.String{Value: agent.AgentPublicIpv4},
-			Status:          types.String{Value: nullableAgentStatusToString(agent.AgentStatus)},
-			IsOnline:        types.Bool{Value: agent.AgentIsOnline},
-			Version:         types.String{Value: agent.AgentVersion},
-			LocationCountry: types.String{Value: nullableStringToString(agent.AgentLocationCountry)},
-			LocationCity:    types.String{Value: nullableStringToString(agent.AgentLocationCity)},
-			DeviceID:        types.String{Value: agent.AgentDeviceId},
-			IsVirtual:       types.Bool{Value: agent.AgentIsVirtual},
-			Type:            types.String{Value: string(agent.AgentType)},
-			ModifiedAt:      types.String{Value: agent.AgentModifiedAt.String()},
-			Tags:            convertAgentTagsToTfValue(agent.AgentTags),
-			AgentProvider: &AgentProvider{
-				ID:   int64(agent.AgentProvider.AgentProviderId),
-				Name: agent.AgentProvider.AgentProviderName,
-			},
+	if len(matches) == 0 {
+		resp.Diagnostics.AddError("Syntropy agent not found", fmt.Sprintf("No agent matches %s", lookup))
+		return
+	}
+
+	if len(matches) > 1 {
+		var found []string
+		for _, agent := range matches {
+			found = append(found, fmt.Sprintf("%s (id=%d)", agent.AgentName, agent.AgentId))
+		}
+		resp.Diagnostics.AddError(
+			"Multiple Syntropy agents found",
+			fmt.Sprintf("Expected 1 agent matching %s, but found several: %s", lookup, strings.Join(found, ", ")),
+		)
+		return
+	}
+
+	agent := convertAgentToTfValue(matches[0])
+	data.ID = agent.ID
+	data.Name = types.String{Value: agent.Name}
+	data.PublicIPv4 = agent.PublicIPv4
+	data.Status = agent.Status
+	data.IsOnline = agent.IsOnline
+	data.Version = agent.Version
+	data.LocationCountry = agent.LocationCountry
+	data.LocationCity = agent.LocationCity
+	data.DeviceID = agent.DeviceID
+	data.IsVirtual = agent.IsVirtual
+	data.Type = agent.Type
+	data.ModifiedAt = agent.ModifiedAt
+	data.Tags = agent.Tags
+	data.AgentProvider = agent.AgentProvider
+
+	agentIDs := []int32{matches[0].AgentId}
+	if data.IncludeServices.Value {
+		services, err := getAgentServices(ctx, *d.provider.client.AgentsApi, agentIDs)
+		if err != nil {
+			resp.Diagnostics.AddError("Error while getting Syntropy agent services", err.Error())
+			return
 		}
+		data.Services = services[data.ID.Value]
+	}
+
+	if data.IncludeConnections.Value {
+		connections, err := getAgentConnections(ctx, *d.provider.client.ConnectionsApi, agentIDs)
+		if err != nil {
+			resp.Diagnostics.AddError("Error while getting Syntropy agent connections", err.Error())
+			return
+		}
+		data.Connections = connections[data.ID.Value]
 	}
 
 	diags = resp.State.Set(ctx, &data)
 	resp.Diagnostics.Append(diags...)
 }
+
+func agentServicesAttribute() tfsdk.Attribute {
+	return tfsdk.Attribute{
+		Description: "Services published by the agent. Returned only if include_services is set",
+		Computed:    true,
+		Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+			"id": {
+				Description: "Agent service ID",
+				Type:        types.Int64Type,
+				Computed:    true,
+			},
+			"name": {
+				Description: "Agent service name",
+				Type:        types.StringType,
+				Computed:    true,
+			},
+			"type": {
+				Description: "Agent service type (Kubernetes, Docker, etc.)",
+				Type:        types.StringType,
+				Computed:    true,
+			},
+			"subnets": {
+				Description: "Agent service subnets",
+				Computed:    true,
+				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+					"id": {
+						Description: "Agent service subnet ID",
+						Type:        types.Int64Type,
+						Computed:    true,
+					},
+					"ip": {
+						Description: "Agent service subnet IP",
+						Type:        types.StringType,
+						Computed:    true,
+					},
+				}),
+			},
+		}),
+	}
+}
+
+func agentConnectionsAttribute() tfsdk.Attribute {
+	return tfsdk.Attribute{
+		Description: "Network connections of the agent. Returned only if include_connections is set",
+		Computed:    true,
+		Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+			"connection_group_id": {
+				Description: "Unique identifier for the connection",
+				Type:        types.Int64Type,
+				Computed:    true,
+			},
+			"peer_agent_id": {
+				Description: "ID of the agent on the other side of the connection",
+				Type:        types.Int64Type,
+				Computed:    true,
+			},
+			"sdn_enabled": {
+				Description: "Is SDN enabled for the connection?",
+				Type:        types.BoolType,
+				Computed:    true,
+			},
+		}),
+	}
+}
+
+// findAgents returns agents matching lookup attribute together with lookup description used in error messages
+func (d agentDataSource) findAgents(ctx context.Context, data AgentDataSource) ([]syntropy.V1Agent, string, error) {
+	if !data.ID.Null {
+		lookup := fmt.Sprintf("id=%d", data.ID.Value)
+		agent, err := getAgentByID(ctx, *d.provider.client.AgentsApi, int32(data.ID.Value))
+		if err == ErrAgentNotFound {
+			return nil, lookup, nil
+		}
+		if err != nil {
+			return nil, lookup, err
+		}
+		return []syntropy.V1Agent{*agent}, lookup, nil
+	}
+
+	// Agents API can not filter by device ID or public IP, so these lookups stop as soon as ambiguity is detected
+	var (
+		search  *string
+		lookup  string
+		limit   = 2
+		matchFn func(agent syntropy.V1Agent) bool
+	)
+	switch {
+	case !data.DeviceID.Null:
+		lookup = fmt.Sprintf("device_id=%q", data.DeviceID.Value)
+		matchFn = func(agent syntropy.V1Agent) bool {
+			return agent.AgentDeviceId == data.DeviceID.Value
+		}
+	case !data.PublicIPv4.Null:
+		lookup = fmt.Sprintf("public_ipv4=%q", data.PublicIPv4.Value)
+		matchFn = func(agent syntropy.V1Agent) bool {
+			return agent.AgentPublicIpv4 == data.PublicIPv4.Value
+		}
+	default:
+		mode := agentNameMatchExact
+		if !data.Match.Null && data.Match.Value != "" {
+			mode = data.Match.Value
+		}
+		search = &data.Name.Value
+		limit = 0
+		lookup = fmt.Sprintf("name=%q (match=%s)", data.Name.Value, mode)
+		matchFn = func(agent syntropy.V1Agent) bool {
+			return agentNameMatches(agent.AgentName, data.Name.Value, mode)
+		}
+	}
+
+	matches, err := searchMatchingAgents(ctx, *d.provider.client.AgentsApi, syntropy.V1NetworkAgentsSearchRequest{Search: search}, matchFn, limit)
+	if err != nil {
+		return nil, lookup, err
+	}
+	return matches, lookup, nil
+}
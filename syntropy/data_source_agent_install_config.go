@@ -0,0 +1,369 @@
+package syntropy
+
+import (
+	"bytes"
+	"context"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v3"
+	"strings"
+	"text/template"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ tfsdk.DataSourceType = agentInstallConfigDataSourceType{}
+var _ tfsdk.DataSource = agentInstallConfigDataSource{}
+
+const (
+	defaultAgentControllerURL = "controller-prod-server.syntropystack.com"
+	defaultAgentImage         = "syntropynet/agent:stable"
+	defaultAgentNetworkMode   = "docker"
+)
+
+type agentInstallConfigDataSourceType struct{}
+
+func (d agentInstallConfigDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		Description: "Datasource renders Syntropy agent installation artifacts",
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Description: "Agent install config ID randomly generated",
+				Type:        types.StringType,
+				Computed:    true,
+			},
+			"token": {
+				Description: "Agent token",
+				Type:        types.StringType,
+				Required:    true,
+				Sensitive:   true,
+			},
+			"name": {
+				Description: "Agent name",
+				Type:        types.StringType,
+				Required:    true,
+			},
+			"tags": {
+				Description: "Agent tags",
+				Optional:    true,
+				Type: types.ListType{
+					ElemType: types.StringType,
+				},
+			},
+			"api_url": {
+				Description: "Syntropy controller URL agent connects to. Defaults to " + defaultAgentControllerURL,
+				Type:        types.StringType,
+				Optional:    true,
+			},
+			"network_mode": {
+				Description: "Network API agent uses to discover services. Possible values: docker, kubernetes, host. Defaults to " + defaultAgentNetworkMode,
+				Type:        types.StringType,
+				Optional:    true,
+				Validators: []tfsdk.AttributeValidator{
+					stringvalidator.OneOf("docker", "kubernetes", "host"),
+				},
+			},
+			"image": {
+				Description: "Agent container image. Defaults to " + defaultAgentImage,
+				Type:        types.StringType,
+				Optional:    true,
+			},
+			"docker_compose": {
+				Description: "docker-compose YAML that runs the agent",
+				Type:        types.StringType,
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"docker_run": {
+				Description: "docker run command that starts the agent",
+				Type:        types.StringType,
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"cloud_init": {
+				Description: "cloud-init user-data document that installs the agent as systemd service",
+				Type:        types.StringType,
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"systemd_unit": {
+				Description: "systemd unit that runs the agent container",
+				Type:        types.StringType,
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"kubernetes_daemonset": {
+				Description: "Kubernetes manifest with agent token secret and DaemonSet",
+				Type:        types.StringType,
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}, nil
+}
+
+func (d agentInstallConfigDataSourceType) NewDataSource(ctx context.Context, in tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
+	provider, diags := convertProviderType(in)
+	return agentInstallConfigDataSource{
+		provider: provider,
+	}, diags
+}
+
+type agentInstallConfigDataSource struct {
+	provider provider
+}
+
+func (d agentInstallConfigDataSource) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	var data AgentInstallConfigDataSource
+	diags := req.Config.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	params := agentInstallParams{
+		Token:         data.Token,
+		Name:          data.Name,
+		Tags:          strings.Join(data.Tags, ","),
+		ControllerURL: defaultAgentControllerURL,
+		NetworkMode:   defaultAgentNetworkMode,
+		Image:         defaultAgentImage,
+	}
+	if !data.ApiURL.Null && data.ApiURL.Value != "" {
+		params.ControllerURL = data.ApiURL.Value
+	}
+	if !data.NetworkMode.Null && data.NetworkMode.Value != "" {
+		params.NetworkMode = data.NetworkMode.Value
+	}
+	if !data.Image.Null && data.Image.Value != "" {
+		params.Image = data.Image.Value
+	}
+
+	// systemd unit is rendered first, because cloud-init document embeds it
+	rendered := []struct {
+		name   string
+		target *types.String
+	}{
+		{"systemd_unit", &data.SystemdUnit},
+		{"cloud_init", &data.CloudInit},
+		{"docker_compose", &data.DockerCompose},
+		{"docker_run", &data.DockerRun},
+		{"kubernetes_daemonset", &data.KubernetesDaemonSet},
+	}
+	for _, r := range rendered {
+		out, err := renderAgentInstallTemplate(r.name, params)
+		if err != nil {
+			resp.Diagnostics.AddError("Error while rendering agent "+r.name, err.Error())
+			return
+		}
+		*r.target = types.String{Value: out}
+		if r.name == "systemd_unit" {
+			params.SystemdUnit = out
+		}
+	}
+
+	data.ID = types.String{Value: uuid.New().String()}
+	diags = resp.State.Set(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+}
+
+type agentInstallParams struct {
+	Token         string
+	Name          string
+	Tags          string
+	ControllerURL string
+	NetworkMode   string
+	Image         string
+	SystemdUnit   string
+}
+
+// Env returns agent environment variables in stable order
+func (p agentInstallParams) Env() [][2]string {
+	env := [][2]string{
+		{"SYNTROPY_CONTROLLER_URL", p.ControllerURL},
+		{"SYNTROPY_AGENT_TOKEN", p.Token},
+		{"SYNTROPY_AGENT_NAME", p.Name},
+		{"SYNTROPY_NETWORK_API", p.NetworkMode},
+	}
+	if p.Tags != "" {
+		env = append(env, [2]string{"SYNTROPY_TAGS", p.Tags})
+	}
+	return env
+}
+
+var agentInstallTemplateFuncs = template.FuncMap{
+	"shell":   shellQuote,
+	"systemd": systemdQuote,
+	"yaml":    yamlQuote,
+	"indent": func(spaces int, s string) string {
+		pad := strings.Repeat(" ", spaces)
+		return pad + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n"+pad)
+	},
+}
+
+var agentInstallTemplates = map[string]string{
+	"docker_compose": `version: "3.8"
+services:
+  syntropy-agent:
+    image: {{ yaml .Image }}
+    container_name: syntropy-agent
+    network_mode: host
+    restart: unless-stopped
+    cap_add:
+      - NET_ADMIN
+      - SYS_MODULE
+    devices:
+      - /dev/net/tun:/dev/net/tun
+    volumes:
+      - /var/run/docker.sock:/var/run/docker.sock:ro
+    environment:
+{{- range .Env }}
+      {{ index . 0 }}: {{ yaml (index . 1) }}
+{{- end }}
+`,
+	"docker_run": `docker run -d --name=syntropy-agent --network=host --restart=unless-stopped \
+  --cap-add=NET_ADMIN --cap-add=SYS_MODULE --device /dev/net/tun:/dev/net/tun \
+  -v /var/run/docker.sock:/var/run/docker.sock:ro \
+{{- range .Env }}
+  -e {{ shell (printf "%s=%s" (index . 0) (index . 1)) }} \
+{{- end }}
+  {{ shell .Image }}
+`,
+	"systemd_unit": `[Unit]
+Description=Syntropy Agent
+After=docker.service
+Requires=docker.service
+
+[Service]
+Restart=always
+RestartSec=10
+ExecStartPre=-/usr/bin/docker rm -f syntropy-agent
+ExecStartPre=/usr/bin/docker pull {{ systemd .Image }}
+ExecStart=/usr/bin/docker run --rm --name=syntropy-agent --network=host \
+  --cap-add=NET_ADMIN --cap-add=SYS_MODULE --device /dev/net/tun:/dev/net/tun \
+  -v /var/run/docker.sock:/var/run/docker.sock:ro \
+{{- range .Env }}
+  -e {{ systemd (printf "%s=%s" (index . 0) (index . 1)) }} \
+{{- end }}
+  {{ systemd .Image }}
+ExecStop=/usr/bin/docker stop syntropy-agent
+
+[Install]
+WantedBy=multi-user.target
+`,
+	"cloud_init": `#cloud-config
+package_update: true
+packages:
+  - docker.io
+write_files:
+  - path: /etc/systemd/system/syntropy-agent.service
+    permissions: "0600"
+    content: |
+{{ indent 6 .SystemdUnit }}
+runcmd:
+  - systemctl enable --now docker
+  - systemctl daemon-reload
+  - systemctl enable --now syntropy-agent
+`,
+	"kubernetes_daemonset": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: syntropy
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: syntropy-agent
+  namespace: syntropy
+type: Opaque
+stringData:
+  token: {{ yaml .Token }}
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: syntropy-agent
+  namespace: syntropy
+spec:
+  selector:
+    matchLabels:
+      app: syntropy-agent
+  template:
+    metadata:
+      labels:
+        app: syntropy-agent
+    spec:
+      hostNetwork: true
+      containers:
+        - name: syntropy-agent
+          image: {{ yaml .Image }}
+          securityContext:
+            capabilities:
+              add:
+                - NET_ADMIN
+                - SYS_MODULE
+          env:
+{{- range .Env }}
+{{- if eq (index . 0) "SYNTROPY_AGENT_TOKEN" }}
+            - name: SYNTROPY_AGENT_TOKEN
+              valueFrom:
+                secretKeyRef:
+                  name: syntropy-agent
+                  key: token
+{{- else }}
+            - name: {{ index . 0 }}
+              value: {{ yaml (index . 1) }}
+{{- end }}
+{{- end }}
+          volumeMounts:
+            - name: tun
+              mountPath: /dev/net/tun
+      volumes:
+        - name: tun
+          hostPath:
+            path: /dev/net/tun
+            type: CharDevice
+`,
+}
+
+func renderAgentInstallTemplate(name string, params agentInstallParams) (string, error) {
+	tmpl, err := template.New(name).Funcs(agentInstallTemplateFuncs).Parse(agentInstallTemplates[name])
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, params); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// shellQuote wraps value in single quotes, so it is passed to command as a single argument
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+var systemdQuoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "%", "%%", "$", "$$")
+
+// systemdQuote wraps value in double quotes for systemd command lines. Specifiers and environment variable references
+// are escaped, so value is passed to command as a single argument without expansion
+func systemdQuote(s string) string {
+	return `"` + systemdQuoteReplacer.Replace(s) + `"`
+}
+
+// yamlQuote encodes value as double quoted YAML scalar, so it can be placed inline at any indentation level
+func yamlQuote(s string) (string, error) {
+	out, err := yaml.Marshal(&yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Style: yaml.DoubleQuotedStyle,
+		Value: s,
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
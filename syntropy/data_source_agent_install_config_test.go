@@ -0,0 +1,66 @@
+package syntropy
+
+import "testing"
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", `''`},
+		{"syntropynet/agent:stable", `'syntropynet/agent:stable'`},
+		{"with space", `'with space'`},
+		{"it's", `'it'"'"'s'`},
+		{"$HOME `id`", "'$HOME `id`'"},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", `""`},
+		{"SYNTROPY_AGENT_NAME=web 1", `"SYNTROPY_AGENT_NAME=web 1"`},
+		{"it's", `"it's"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\path`, `"C:\\path"`},
+		{"100%", `"100%%"`},
+		{"$HOME ${USER}", `"$$HOME $${USER}"`},
+		{"a\nb\tc", `"a\nb\tc"`},
+	}
+	for _, tt := range tests {
+		if got := systemdQuote(tt.in); got != tt.want {
+			t.Errorf("systemdQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestYamlQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", `""`},
+		{"syntropynet/agent:stable", `"syntropynet/agent:stable"`},
+		{"yes", `"yes"`},
+		{"café", `"café"`},
+		{`say "hi" \ bye`, `"say \"hi\" \\ bye"`},
+		{"a\nb", `"a\nb"`},
+	}
+	for _, tt := range tests {
+		got, err := yamlQuote(tt.in)
+		if err != nil {
+			t.Errorf("yamlQuote(%q) returned error: %s", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("yamlQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
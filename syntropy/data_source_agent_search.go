@@ -2,16 +2,41 @@ package syntropy
 
 import (
 	"context"
+	"fmt"
 	"github.com/SyntropyNet/syntropy-sdk-go/syntropy"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces
 var _ tfsdk.DataSourceType = agentSearchDataSourceType{}
 var _ tfsdk.DataSource = agentSearchDataSource{}
 
+const (
+	defaultAgentSearchMaxResults = 10000
+
+	defaultAgentSearchWaitTimeout = 10 * time.Minute
+
+	agentOrderFieldID         = "id"
+	agentOrderFieldName       = "name"
+	agentOrderFieldModifiedAt = "modified_at"
+	agentOrderFieldVersion    = "version"
+	agentOrderFieldLocation   = "location"
+
+	agentOrderAsc  = "asc"
+	agentOrderDesc = "desc"
+)
+
 type agentSearchDataSourceType struct{}
 
 func (d agentSearchDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
@@ -21,12 +46,31 @@ func (d agentSearchDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, d
 			"skip": {
 				Type:        types.Int64Type,
 				Optional:    true,
-				Description: "Number of items to skip",
+				Description: "Number of matching agents to skip",
+				Validators: []tfsdk.AttributeValidator{
+					int64validator.AtLeast(0),
+				},
 			},
 			"take": {
 				Type:        types.Int64Type,
 				Optional:    true,
-				Description: "Number of items to take",
+				Description: "Number of matching agents to take. If not set, all matching agents are returned. If set, only requested page is fetched, unless filters evaluated by provider (tags_all, tags_any, tags_none, name_regex, public_ip_cidrs, online_only, stale_after) are set or agents are ordered by version",
+				Validators: []tfsdk.AttributeValidator{
+					int64validator.AtLeast(1),
+				},
+			},
+			"max_results": {
+				Type:        types.Int64Type,
+				Optional:    true,
+				Description: fmt.Sprintf("Maximum number of matching agents to fetch when all matching agents are fetched. Search fails if more agents match. Defaults to %d", defaultAgentSearchMaxResults),
+				Validators: []tfsdk.AttributeValidator{
+					int64validator.AtLeast(1),
+				},
+			},
+			"total_count": {
+				Type:        types.Int64Type,
+				Computed:    true,
+				Description: "Total number of agents matching search, before skip and take are applied. Not set if only requested page is fetched",
 			},
 			"search": {
 				Type:        types.StringType,
@@ -34,6 +78,59 @@ func (d agentSearchDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, d
 				Computed:    true,
 				Description: "Agent name pattern. This will be used to filter out agent names that doesn't have specified patter",
 			},
+			"wait_for_count": {
+				Type:        types.Int64Type,
+				Optional:    true,
+				Description: "Minimum number of agents matching search to wait for. Search is retried with backoff until enough agents are registered or wait_timeout is reached",
+				Validators: []tfsdk.AttributeValidator{
+					int64validator.AtLeast(1),
+				},
+			},
+			"wait_for_online": {
+				Type:        types.BoolType,
+				Optional:    true,
+				Description: "Should only online agents be counted when waiting for wait_for_count agents?",
+			},
+			"wait_timeout": {
+				Type:        types.StringType,
+				Optional:    true,
+				Description: "How long to wait for wait_for_count agents, e.g. \"5m\" or \"1h\". Defaults to 10m",
+				Validators: []tfsdk.AttributeValidator{
+					durationValidator{},
+				},
+			},
+			"include_services": {
+				Type:        types.BoolType,
+				Optional:    true,
+				Description: "Should services of returned agents be included?",
+			},
+			"include_connections": {
+				Type:        types.BoolType,
+				Optional:    true,
+				Description: "Should connections of returned agents be included?",
+			},
+			"order": {
+				Description: "Order of returned agents. Defaults to ascending order by agent ID",
+				Optional:    true,
+				Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+					"field": {
+						Description: "Field to order agents by. Possible values: id, name, modified_at, version, location. Versions are compared semantically, e.g. 1.9 comes before 1.10",
+						Required:    true,
+						Type:        types.StringType,
+						Validators: []tfsdk.AttributeValidator{
+							stringvalidator.OneOf(agentOrderFieldID, agentOrderFieldName, agentOrderFieldModifiedAt, agentOrderFieldVersion, agentOrderFieldLocation),
+						},
+					},
+					"direction": {
+						Description: "Order direction. Possible values: asc, desc. Defaults to asc",
+						Optional:    true,
+						Type:        types.StringType,
+						Validators: []tfsdk.AttributeValidator{
+							stringvalidator.OneOf(agentOrderAsc, agentOrderDesc),
+						},
+					},
+				}),
+			},
 			"filter": {
 				Description: "Syntropy agent search filter",
 				Optional:    true,
@@ -80,6 +177,27 @@ func (d agentSearchDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, d
 							ElemType: types.StringType,
 						},
 					},
+					"tags_all": {
+						Description: "Filter agents that have all of these tag names",
+						Optional:    true,
+						Type: types.SetType{
+							ElemType: types.StringType,
+						},
+					},
+					"tags_any": {
+						Description: "Filter agents that have at least one of these tag names",
+						Optional:    true,
+						Type: types.SetType{
+							ElemType: types.StringType,
+						},
+					},
+					"tags_none": {
+						Description: "Filter agents that have none of these tag names",
+						Optional:    true,
+						Type: types.SetType{
+							ElemType: types.StringType,
+						},
+					},
 					"status": {
 						Description: "Filter by agent status",
 						Optional:    true,
@@ -95,123 +213,212 @@ func (d agentSearchDataSourceType) GetSchema(_ context.Context) (tfsdk.Schema, d
 						},
 					},
 					"modified_at_from": {
-						Description: "Filter by agent modified at from date",
+						Description: "Filter by agent modified at from date. Accepts RFC3339 timestamp or relative duration, e.g. \"-24h\" or \"7d ago\"",
 						Optional:    true,
 						Type:        types.StringType,
+						Validators: []tfsdk.AttributeValidator{
+							dateExpressionValidator{},
+						},
 					},
 					"modified_at_to": {
-						Description: "Filter by agent modified at to date",
+						Description: "Filter by agent modified at to date. Accepts RFC3339 timestamp or relative duration, e.g. \"-24h\" or \"7d ago\"",
 						Optional:    true,
 						Type:        types.StringType,
+						Validators: []tfsdk.AttributeValidator{
+							dateExpressionValidator{},
+						},
 					},
 					"name": {
 						Description: "Filter by agent name",
 						Optional:    true,
 						Type:        types.StringType,
 					},
-				}),
-			},
-			"agents": {
-				Computed: true,
-				Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
-					"id": {
-						Description: "Unique identifier for the agent",
-						Computed:    true,
-						Type:        types.Int64Type,
-					},
-					"name": {
-						Description: "Name of the agent as it appears in Platform UI",
-						Computed:    true,
-						Type:        types.StringType,
-					},
-					"public_ipv4": {
-						Description: "IP address of the agent in IPv4 format",
-						Type:        types.StringType,
-						Computed:    true,
-					},
-					"status": {
-						Description: "Current status of the agent.",
-						Type:        types.StringType,
-						Computed:    true,
-					},
-					"is_online": {
-						Description: "Current status of the agent.",
-						Type:        types.BoolType,
-						Computed:    true,
-					},
-					"version": {
-						Description: "Version of the agent.",
-						Type:        types.StringType,
-						Computed:    true,
-					},
-					"location_country": {
-						Description: "Agent's location country two-letter code.",
-						Type:        types.StringType,
-						Computed:    true,
-					},
-					"location_city": {
-						Description: "City, where your agent is based",
-						Type:        types.StringType,
-						Computed:    true,
-					},
-					"device_id": {
-						Description: "A unique agent identifier. Usually machine id or other unique UUID with a workspace id prefix (to scope this agent to workspace).",
+					"name_regex": {
+						Description: "Filter agents which name matches regular expression",
+						Optional:    true,
 						Type:        types.StringType,
-						Computed:    true,
+						Validators: []tfsdk.AttributeValidator{
+							regexValidator{},
+						},
 					},
-					"is_virtual": {
-						Description: "Indicates if it's a virtual agent.",
+					"online_only": {
+						Description: "Filter agents that are online",
+						Optional:    true,
 						Type:        types.BoolType,
-						Computed:    true,
 					},
-					"type": {
-						Description: "Possible types: LINUX, MACOS, WINDOWS, VIRTUAL",
+					"stale_after": {
+						Description: "Filter out agents that were not modified for longer than this duration, e.g. \"30m\", \"12h\" or \"7d\"",
+						Optional:    true,
 						Type:        types.StringType,
-						Computed:    true,
+						Validators: []tfsdk.AttributeValidator{
+							durationValidator{},
+						},
 					},
-					"modified_at": {
-						Description: "Date and time when this agent was modified. Formatted as an ISO 8601 date time string.",
-						Type:        types.StringType,
-						Computed:    true,
+					"public_ip_cidrs": {
+						Description: "Filter agents which public IPv4 address belongs to one of these networks in CIDR notation",
+						Optional:    true,
+						Type: types.SetType{
+							ElemType: types.StringType,
+						},
+						Validators: []tfsdk.AttributeValidator{
+							setvalidator.ValuesAre(cidrValidator{}),
+						},
 					},
-					"tags": {
-						Description: "Agent specific words that can help you to create some rules around specific tags.",
-						Computed:    true,
-						Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
-							"id": {
-								Description: "Agent tag id",
-								Type:        types.Int64Type,
-								Computed:    true,
-							},
-							"name": {
-								Description: "Agent tag name",
-								Type:        types.StringType,
-								Computed:    true,
-							},
-						}),
+				}),
+			},
+			"agents": {
+				Description: "List of agents matching search",
+				Computed:    true,
+				Attributes:  tfsdk.ListNestedAttributes(agentDataAttributes()),
+			},
+			"ids": {
+				Description: "Set of IDs of agents matching search",
+				Computed:    true,
+				Type: types.SetType{
+					ElemType: types.Int64Type,
+				},
+			},
+			"offline_ids": {
+				Description: "List of IDs of agents matching search that are offline. Online and staleness filters are not applied to this list. When all matching agents are fetched, skip and take are not applied either, so the list covers the complete result while ids covers the requested page only. When only requested page is fetched, the list covers that page",
+				Computed:    true,
+				Type: types.ListType{
+					ElemType: types.Int64Type,
+				},
+			},
+			"names": {
+				Description: "List of names of agents matching search",
+				Computed:    true,
+				Type: types.ListType{
+					ElemType: types.StringType,
+				},
+			},
+			"by_name": {
+				Description: "Map of agent name to agent matching search. If several agents have the same name, the first one is used",
+				Computed:    true,
+				Attributes:  tfsdk.MapNestedAttributes(agentDataAttributes()),
+			},
+			"by_tag": {
+				Description: "Map of tag name to IDs of agents matching search that have this tag",
+				Computed:    true,
+				Type: types.MapType{
+					ElemType: types.ListType{
+						ElemType: types.Int64Type,
 					},
-					"provider": {
-						Description: "Returns provider of agent's endpoint",
-						Computed:    true,
-						Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
-							"id": {
-								Description: "Agent provider id",
-								Type:        types.Int64Type,
-								Computed:    true,
-							},
-							"name": {
-								Description: "Agent provider name",
-								Type:        types.StringType,
-								Computed:    true,
-							},
-						}),
+				},
+			},
+			"by_country": {
+				Description: "Map of location country code to IDs of agents matching search that are located in this country",
+				Computed:    true,
+				Type: types.MapType{
+					ElemType: types.ListType{
+						ElemType: types.Int64Type,
 					},
-				}),
+				},
 			},
 		},
 	}, nil
 }
 
+// agentDataAttributes returns schema of agent object returned by search
+func agentDataAttributes() map[string]tfsdk.Attribute {
+	return map[string]tfsdk.Attribute{
+		"id": {
+			Description: "Unique identifier for the agent",
+			Computed:    true,
+			Type:        types.Int64Type,
+		},
+		"name": {
+			Description: "Name of the agent as it appears in Platform UI",
+			Computed:    true,
+			Type:        types.StringType,
+		},
+		"public_ipv4": {
+			Description: "IP address of the agent in IPv4 format",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"status": {
+			Description: "Current status of the agent.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"is_online": {
+			Description: "Current status of the agent.",
+			Type:        types.BoolType,
+			Computed:    true,
+		},
+		"version": {
+			Description: "Version of the agent.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"location_country": {
+			Description: "Agent's location country two-letter code.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"location_city": {
+			Description: "City, where your agent is based",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"device_id": {
+			Description: "A unique agent identifier. Usually machine id or other unique UUID with a workspace id prefix (to scope this agent to workspace).",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"is_virtual": {
+			Description: "Indicates if it's a virtual agent.",
+			Type:        types.BoolType,
+			Computed:    true,
+		},
+		"type": {
+			Description: "Possible types: LINUX, MACOS, WINDOWS, VIRTUAL",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"modified_at": {
+			Description: "Date and time when this agent was modified. Formatted as an ISO 8601 date time string.",
+			Type:        types.StringType,
+			Computed:    true,
+		},
+		"tags": {
+			Description: "Agent specific words that can help you to create some rules around specific tags.",
+			Computed:    true,
+			Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+				"id": {
+					Description: "Agent tag id",
+					Type:        types.Int64Type,
+					Computed:    true,
+				},
+				"name": {
+					Description: "Agent tag name",
+					Type:        types.StringType,
+					Computed:    true,
+				},
+			}),
+		},
+		"services":    agentServicesAttribute(),
+		"connections": agentConnectionsAttribute(),
+		"provider": {
+			Description: "Returns provider of agent's endpoint",
+			Computed:    true,
+			Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+				"id": {
+					Description: "Agent provider id",
+					Type:        types.Int64Type,
+					Computed:    true,
+				},
+				"name": {
+					Description: "Agent provider name",
+					Type:        types.StringType,
+					Computed:    true,
+				},
+			}),
+		},
+	}
+}
+
 func (d agentSearchDataSourceType) NewDataSource(ctx context.Context, in tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
 	provider, diags := convertProviderType(in)
 	return agentSearchDataSource{
@@ -232,55 +439,500 @@ func (d agentSearchDataSource) Read(ctx context.Context, req tfsdk.ReadDataSourc
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	agentFilter := &syntropy.V1AgentFilter{}
 
+	maxResults := defaultAgentSearchMaxResults
+	if !data.MaxResults.Null {
+		maxResults = int(data.MaxResults.Value)
+	}
+
+	order := AgentOrder{
+		Field:     types.String{Value: agentOrderFieldID},
+		Direction: types.String{Value: agentOrderAsc},
+	}
+	if data.Order != nil {
+		order.Field = data.Order.Field
+		if !data.Order.Direction.Null {
+			order.Direction = data.Order.Direction
+		}
+	}
+
+	waitTimeout := defaultAgentSearchWaitTimeout
+	if !data.WaitTimeout.Null {
+		timeout, err := parseRelativeDuration(data.WaitTimeout.Value)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("wait_timeout"), "Error while parsing wait timeout", err.Error())
+			return
+		}
+		waitTimeout = timeout
+	}
+
+	var (
+		agents     []syntropy.V1Agent
+		offlineIDs []int64
+		paged      bool
+		ready      int
+	)
+	err := pollWithBackoff(ctx, waitTimeout, func() (bool, error) {
+		agents, offlineIDs, paged, diags = d.searchAgents(ctx, data, order, maxResults)
+		resp.Diagnostics.Append(diags...)
+		if diags.HasError() {
+			// Search errors are already reported, so polling is just stopped
+			return true, nil
+		}
+
+		ready = countReadyAgents(agents, data.WaitForOnline.Value)
+		return data.WaitForCount.Null || ready >= int(data.WaitForCount.Value), nil
+	})
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err == ErrWaitTimeout {
+		resp.Diagnostics.AddError("Timeout while waiting for Syntropy agents", waitForAgentsErrorMessage(data, agents, ready, waitTimeout))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Waiting for Syntropy agents was cancelled", err.Error())
+		return
+	}
+
+	data.OfflineIDs = offlineIDs
+	data.IDs = []int64{}
+	data.Names = []string{}
+	data.ByName = map[string]AgentData{}
+	data.ByTag = map[string][]int64{}
+	data.ByCountry = map[string][]int64{}
+	if paged {
+		// Only requested page is fetched, so total number of matching agents is unknown
+		data.TotalCount = types.Int64{Null: true}
+	} else {
+		data.TotalCount = types.Int64{Value: int64(len(agents))}
+		agents = paginateAgents(agents, int(data.Skip.Value), int(data.Take.Value))
+	}
+
+	var agentIDs []int32
+	for _, agent := range agents {
+		agentIDs = append(agentIDs, agent.AgentId)
+	}
+
+	var (
+		services    map[int64][]AgentService
+		connections map[int64][]AgentConnection
+	)
+	if data.IncludeServices.Value && len(agentIDs) > 0 {
+		services, err = getAgentServices(ctx, *d.provider.client.AgentsApi, agentIDs)
+		if err != nil {
+			resp.Diagnostics.AddError("Error while getting Syntropy agent services", err.Error())
+			return
+		}
+	}
+	if data.IncludeConnections.Value && len(agentIDs) > 0 {
+		connections, err = getAgentConnections(ctx, *d.provider.client.ConnectionsApi, agentIDs)
+		if err != nil {
+			resp.Diagnostics.AddError("Error while getting Syntropy agent connections", err.Error())
+			return
+		}
+	}
+
+	for _, agent := range agents {
+		agentData := convertAgentToTfValue(agent)
+		if data.IncludeServices.Value {
+			agentData.Services = services[agentData.ID.Value]
+		}
+		if data.IncludeConnections.Value {
+			agentData.Connections = connections[agentData.ID.Value]
+		}
+		data.Agents = append(data.Agents, agentData)
+
+		data.IDs = append(data.IDs, agentData.ID.Value)
+		data.Names = append(data.Names, agentData.Name)
+		if _, ok := data.ByName[agentData.Name]; !ok {
+			data.ByName[agentData.Name] = agentData
+		}
+		for _, tag := range agentData.Tags {
+			data.ByTag[tag.Name] = append(data.ByTag[tag.Name], agentData.ID.Value)
+		}
+		if country := agentData.LocationCountry.Value; country != "" {
+			data.ByCountry[country] = append(data.ByCountry[country], agentData.ID.Value)
+		}
+	}
+
+	diags = resp.State.Set(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+}
+
+// searchAgents fetches agents matching search and applies client side filters. Returned agents are sorted and
+// health filters are already applied, while offline IDs are collected before health filters. If take is set and all
+// filters are evaluated by API, only requested page is fetched and true is returned. Otherwise all matching agents are
+// fetched and skip and take are left for the caller
+func (d agentSearchDataSource) searchAgents(ctx context.Context, data AgentSearchDataSource, order AgentOrder, maxResults int) ([]syntropy.V1Agent, []int64, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	agentFilter := &syntropy.V1AgentFilter{}
+	clientFilter := agentClientFilter{}
 	if data.Filter != nil {
 		filter, err := flattenAgentFilter(*data.Filter)
 		if err != nil {
-			resp.Diagnostics.AddError("Error while parsing agent filter data", err.Error())
-			return
+			diags.AddAttributeError(path.Root("filter"), "Error while parsing agent filter data", err.Error())
+			return nil, nil, false, diags
 		}
 		agentFilter = filter
+
+		clientFilter, err = newAgentClientFilter(*data.Filter, time.Now())
+		if err != nil {
+			diags.AddAttributeError(path.Root("filter"), "Error while parsing agent filter data", err.Error())
+			return nil, nil, false, diags
+		}
 	}
 
-	skip := int32(data.Skip.Value)
-	take := int32(data.Take.Value)
-	aResp, _, err := d.provider.client.AgentsApi.V1NetworkAgentsSearch(ctx).V1NetworkAgentsSearchRequest(syntropy.V1NetworkAgentsSearchRequest{
+	searchRequest := syntropy.V1NetworkAgentsSearchRequest{
 		Filter: agentFilter,
-		Order:  nil,
-		Skip:   &skip,
-		Take:   &take,
+		Order:  flattenAgentOrder(order),
 		Search: &data.Search.Value,
-	}).Execute()
+	}
+
+	// API compares versions as strings, so agents ordered by version are sorted on provider side and need all pages
+	if !data.Take.Null && clientFilter.empty() && order.Field.Value != agentOrderFieldVersion {
+		skip := int32(data.Skip.Value)
+		take := int32(data.Take.Value)
+		searchRequest.Skip = &skip
+		searchRequest.Take = &take
+		aResp, _, err := d.provider.client.AgentsApi.V1NetworkAgentsSearch(ctx).V1NetworkAgentsSearchRequest(searchRequest).Execute()
+		if err != nil {
+			diags.AddError("Error while getting Syntropy agent", err.Error())
+			return nil, nil, false, diags
+		}
+
+		offlineIDs := []int64{}
+		for _, agent := range aResp.Data {
+			if !agent.AgentIsOnline {
+				offlineIDs = append(offlineIDs, int64(agent.AgentId))
+			}
+		}
+		return aResp.Data, offlineIDs, true, diags
+	}
+
+	agents, err := searchAllAgents(ctx, *d.provider.client.AgentsApi, searchRequest, maxResults)
 	if err != nil {
-		resp.Diagnostics.AddError("Error while getting Syntropy agent", err.Error())
-		return
+		if err == ErrTooManyAgents {
+			diags.AddAttributeError(
+				path.Root("max_results"),
+				"Too many Syntropy agents match search",
+				fmt.Sprintf("More than %d agents match search. Narrow down search filter or increase max_results", maxResults),
+			)
+			return nil, nil, false, diags
+		}
+		diags.AddError("Error while getting Syntropy agent", err.Error())
+		return nil, nil, false, diags
 	}
 
-	for _, agent := range aResp.Data {
-		data.Agents = append(data.Agents, AgentData{
-			ID:              types.Int64{Value: int64(agent.AgentId)},
-			Name:            agent.AgentName,
-			PublicIPv4:      types.String{Value: agent.AgentPublicIpv4},
-			Status:          types.String{Value: nullableAgentStatusToString(agent.AgentStatus)},
-			IsOnline:        types.Bool{Value: agent.AgentIsOnline},
-			Version:         types.String{Value: agent.AgentVersion},
-			LocationCountry: types.String{Value: nullableStringToString(agent.AgentLocationCountry)},
-			LocationCity:    types.String{Value: nullableStringToString(agent.AgentLocationCity)},
-			DeviceID:        types.String{Value: agent.AgentDeviceId},
-			IsVirtual:       types.Bool{Value: agent.AgentIsVirtual},
-			Type:            types.String{Value: string(agent.AgentType)},
-			ModifiedAt:      types.String{Value: agent.AgentModifiedAt.String()},
-			Tags:            convertAgentTagsToTfValue(agent.AgentTags),
-			AgentProvider: &AgentProvider{
-				ID:   int64(agent.AgentProvider.AgentProviderId),
-				Name: agent.AgentProvider.AgentProviderName,
-			},
-		})
+	agents = clientFilter.apply(agents)
+
+	if order.Field.Value == agentOrderFieldVersion {
+		sortAgentsByVersion(agents, order.Direction.Value == agentOrderDesc)
 	}
 
-	diags = resp.State.Set(ctx, &data)
-	resp.Diagnostics.Append(diags...)
+	// Offline agents are collected before health filters are applied, so they are reported even if only online
+	// agents are returned
+	offlineIDs := []int64{}
+	for _, agent := range agents {
+		if !agent.AgentIsOnline {
+			offlineIDs = append(offlineIDs, int64(agent.AgentId))
+		}
+	}
+	return clientFilter.applyHealth(agents), offlineIDs, false, diags
+}
+
+func countReadyAgents(agents []syntropy.V1Agent, onlineOnly bool) int {
+	if !onlineOnly {
+		return len(agents)
+	}
+	count := 0
+	for _, agent := range agents {
+		if agent.AgentIsOnline {
+			count++
+		}
+	}
+	return count
+}
+
+// waitForAgentsErrorMessage describes why waiting for agents timed out. Agents requested by name or ID that were not
+// found are listed, and search criteria are included, so agents expected by other filters can be checked
+func waitForAgentsErrorMessage(data AgentSearchDataSource, agents []syntropy.V1Agent, ready int, timeout time.Duration) string {
+	state := "matching"
+	if data.WaitForOnline.Value {
+		state = "online"
+	}
+	msg := fmt.Sprintf("Only %d of %d expected %s agents found after %s", ready, data.WaitForCount.Value, state, timeout)
+
+	names := map[string]bool{}
+	ids := map[int64]bool{}
+	for _, agent := range agents {
+		if agent.AgentIsOnline || !data.WaitForOnline.Value {
+			names[agent.AgentName] = true
+			ids[int64(agent.AgentId)] = true
+		}
+	}
+
+	var missing []string
+	if data.Filter != nil && data.Filter.Name != nil && *data.Filter.Name != "" && !names[*data.Filter.Name] {
+		missing = append(missing, fmt.Sprintf("name=%q", *data.Filter.Name))
+	}
+	if data.Filter != nil && data.Filter.ID != nil {
+		for _, id := range *data.Filter.ID {
+			if !ids[id] {
+				missing = append(missing, fmt.Sprintf("id=%d", id))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		msg += fmt.Sprintf(". Missing agents: %s", strings.Join(missing, ", "))
+	}
+
+	if criteria := agentSearchCriteria(data); len(criteria) > 0 {
+		msg += fmt.Sprintf(". Search criteria: %s", strings.Join(criteria, ", "))
+	}
+	return msg
+}
+
+// agentSearchCriteria describes search and all filters that are set, e.g. `filter.tags_all=["prod" "eu"]`
+func agentSearchCriteria(data AgentSearchDataSource) []string {
+	var criteria []string
+	add := func(name string, value interface{}) {
+		criteria = append(criteria, fmt.Sprintf("%s=%q", name, value))
+	}
+	if data.Search.Value != "" {
+		add("search", data.Search.Value)
+	}
+	if data.Filter == nil {
+		return criteria
+	}
+
+	f := data.Filter
+	for _, v := range []struct {
+		name  string
+		value *[]string
+	}{
+		{"filter.type", f.Type},
+		{"filter.version", f.Version},
+		{"filter.tag_name", f.TagName},
+		{"filter.tags_all", f.TagsAll},
+		{"filter.tags_any", f.TagsAny},
+		{"filter.tags_none", f.TagsNone},
+		{"filter.status", f.Status},
+		{"filter.location_country", f.LocationCountry},
+		{"filter.public_ip_cidrs", f.PublicIPCIDRs},
+	} {
+		if v.value != nil && len(*v.value) > 0 {
+			add(v.name, *v.value)
+		}
+	}
+	for _, v := range []struct {
+		name  string
+		value *[]int64
+	}{
+		{"filter.id", f.ID},
+		{"filter.tag_id", f.TagID},
+		{"filter.provider_id", f.ProviderID},
+	} {
+		if v.value != nil && len(*v.value) > 0 {
+			criteria = append(criteria, fmt.Sprintf("%s=%v", v.name, *v.value))
+		}
+	}
+	for _, v := range []struct {
+		name  string
+		value *string
+	}{
+		{"filter.name", f.Name},
+		{"filter.name_regex", f.NameRegex},
+		{"filter.modified_at_from", f.ModifiedAtFrom},
+		{"filter.modified_at_to", f.ModifiedAtTo},
+		{"filter.stale_after", f.StaleAfter},
+	} {
+		if v.value != nil && *v.value != "" {
+			add(v.name, *v.value)
+		}
+	}
+	if f.OnlineOnly != nil && *f.OnlineOnly {
+		criteria = append(criteria, "filter.online_only=true")
+	}
+	return criteria
+}
+
+func flattenAgentOrder(in AgentOrder) *syntropy.V1AgentOrder {
+	direction := syntropy.OrderDirection(strings.ToUpper(in.Direction.Value))
+	out := &syntropy.V1AgentOrder{}
+	switch in.Field.Value {
+	case agentOrderFieldName:
+		out.AgentName = &direction
+	case agentOrderFieldModifiedAt:
+		out.AgentModifiedAt = &direction
+	case agentOrderFieldVersion:
+		out.AgentVersion = &direction
+	case agentOrderFieldLocation:
+		out.AgentLocationCountry = &direction
+	default:
+		out.AgentId = &direction
+	}
+	return out
+}
+
+// sortAgentsByVersion stable sorts agents by semantic version. Agents with equal versions keep API order
+func sortAgentsByVersion(agents []syntropy.V1Agent, desc bool) {
+	sort.SliceStable(agents, func(i, j int) bool {
+		c := compareVersions(agents[i].AgentVersion, agents[j].AgentVersion)
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// paginateAgents applies skip and take to already fetched agents. Zero take means all remaining agents
+func paginateAgents(agents []syntropy.V1Agent, skip, take int) []syntropy.V1Agent {
+	if skip >= len(agents) {
+		return nil
+	}
+	agents = agents[skip:]
+	if take > 0 && take < len(agents) {
+		agents = agents[:take]
+	}
+	return agents
+}
+
+// agentClientFilter evaluates agent filters that are not supported by API
+type agentClientFilter struct {
+	tagsAll        []string
+	tagsAny        []string
+	tagsNone       []string
+	nameRegex      *regexp.Regexp
+	publicIPv4Nets []*net.IPNet
+	onlineOnly     bool
+	staleBefore    *time.Time
+}
+
+func newAgentClientFilter(in AgentFilter, now time.Time) (agentClientFilter, error) {
+	out := agentClientFilter{}
+	if in.TagsAll != nil {
+		out.tagsAll = *in.TagsAll
+	}
+	if in.TagsAny != nil {
+		out.tagsAny = *in.TagsAny
+	}
+	if in.TagsNone != nil {
+		out.tagsNone = *in.TagsNone
+	}
+	if in.NameRegex != nil {
+		nameRegex, err := regexp.Compile(*in.NameRegex)
+		if err != nil {
+			return out, err
+		}
+		out.nameRegex = nameRegex
+	}
+	if in.PublicIPCIDRs != nil {
+		for _, cidr := range *in.PublicIPCIDRs {
+			_, ipNet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return out, err
+			}
+			out.publicIPv4Nets = append(out.publicIPv4Nets, ipNet)
+		}
+	}
+	if in.OnlineOnly != nil {
+		out.onlineOnly = *in.OnlineOnly
+	}
+	if in.StaleAfter != nil {
+		staleAfter, err := parseRelativeDuration(*in.StaleAfter)
+		if err != nil {
+			return out, err
+		}
+		staleBefore := now.Add(-staleAfter)
+		out.staleBefore = &staleBefore
+	}
+	return out, nil
+}
+
+// empty reports whether there are no filters to evaluate on provider side, so API paging can be used as is
+func (f agentClientFilter) empty() bool {
+	return len(f.tagsAll) == 0 && len(f.tagsAny) == 0 && len(f.tagsNone) == 0 && f.nameRegex == nil &&
+		len(f.publicIPv4Nets) == 0 && !f.onlineOnly && f.staleBefore == nil
+}
+
+func (f agentClientFilter) apply(agents []syntropy.V1Agent) []syntropy.V1Agent {
+	var out []syntropy.V1Agent
+	for _, agent := range agents {
+		if f.matches(agent) {
+			out = append(out, agent)
+		}
+	}
+	return out
+}
+
+// applyHealth filters out offline and stale agents
+func (f agentClientFilter) applyHealth(agents []syntropy.V1Agent) []syntropy.V1Agent {
+	var out []syntropy.V1Agent
+	for _, agent := range agents {
+		if f.onlineOnly && !agent.AgentIsOnline {
+			continue
+		}
+		if f.staleBefore != nil && agent.AgentModifiedAt.Before(*f.staleBefore) {
+			continue
+		}
+		out = append(out, agent)
+	}
+	return out
+}
+
+func (f agentClientFilter) matches(agent syntropy.V1Agent) bool {
+	tags := make(map[string]bool, len(agent.AgentTags))
+	for _, tag := range agent.AgentTags {
+		tags[tag.AgentTagName] = true
+	}
+
+	for _, tag := range f.tagsAll {
+		if !tags[tag] {
+			return false
+		}
+	}
+
+	if len(f.tagsAny) > 0 {
+		found := false
+		for _, tag := range f.tagsAny {
+			if tags[tag] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, tag := range f.tagsNone {
+		if tags[tag] {
+			return false
+		}
+	}
+
+	if f.nameRegex != nil && !f.nameRegex.MatchString(agent.AgentName) {
+		return false
+	}
+
+	if len(f.publicIPv4Nets) > 0 {
+		ip := net.ParseIP(agent.AgentPublicIpv4)
+		if ip == nil {
+			return false
+		}
+		found := false
+		for _, ipNet := range f.publicIPv4Nets {
+			if ipNet.Contains(ip) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 func flattenAgentFilter(in AgentFilter) (*syntropy.V1AgentFilter, error) {
@@ -308,8 +960,15 @@ func flattenAgentFilter(in AgentFilter) (*syntropy.V1AgentFilter, error) {
 		out.AgentVersion = *in.Version
 	}
 
-	if in.TagName != nil {
+	// API tag name filter matches agents with any of given tags. It is used for tags_any or tags_all
+	// to narrow down fetched agents, while exact semantics are evaluated by agentClientFilter
+	switch {
+	case in.TagName != nil:
 		out.AgentTagName = *in.TagName
+	case in.TagsAny != nil && len(*in.TagsAny) > 0:
+		out.AgentTagName = *in.TagsAny
+	case in.TagsAll != nil && len(*in.TagsAll) > 0:
+		out.AgentTagName = *in.TagsAll
 	}
 
 	if in.LocationCountry != nil {
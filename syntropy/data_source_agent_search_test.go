@@ -0,0 +1,183 @@
+package syntropy
+
+import (
+	"github.com/SyntropyNet/syntropy-sdk-go/syntropy"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testAgents(ids ...int32) []syntropy.V1Agent {
+	var agents []syntropy.V1Agent
+	for _, id := range ids {
+		agents = append(agents, syntropy.V1Agent{AgentId: id})
+	}
+	return agents
+}
+
+func testAgentIDs(agents []syntropy.V1Agent) []int32 {
+	var ids []int32
+	for _, agent := range agents {
+		ids = append(ids, agent.AgentId)
+	}
+	return ids
+}
+
+func TestPaginateAgents(t *testing.T) {
+	tests := []struct {
+		name string
+		skip int
+		take int
+		want []int32
+	}{
+		{"all", 0, 0, []int32{1, 2, 3, 4, 5}},
+		{"skip only", 2, 0, []int32{3, 4, 5}},
+		{"take only", 0, 2, []int32{1, 2}},
+		{"skip and take", 1, 3, []int32{2, 3, 4}},
+		{"take past end", 3, 10, []int32{4, 5}},
+		{"skip past end", 5, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testAgentIDs(paginateAgents(testAgents(1, 2, 3, 4, 5), tt.skip, tt.take))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("paginateAgents(skip=%d, take=%d) = %v, want %v", tt.skip, tt.take, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortAgentsByVersion(t *testing.T) {
+	versions := map[int32]string{1: "1.10.0", 2: "1.9.1", 3: "1.10.0-rc1", 4: "1.9.1", 5: "0.3.2"}
+	tests := []struct {
+		name string
+		desc bool
+		want []int32
+	}{
+		{"asc", false, []int32{5, 2, 4, 3, 1}},
+		{"desc", true, []int32{1, 3, 2, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agents := testAgents(1, 2, 3, 4, 5)
+			for i := range agents {
+				agents[i].AgentVersion = versions[agents[i].AgentId]
+			}
+			sortAgentsByVersion(agents, tt.desc)
+			if got := testAgentIDs(agents); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortAgentsByVersion(desc=%v) = %v, want %v", tt.desc, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentClientFilter(t *testing.T) {
+	now := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	agents := []syntropy.V1Agent{
+		{AgentId: 1, AgentName: "web-eu-1", AgentPublicIpv4: "10.0.0.1", AgentIsOnline: true, AgentModifiedAt: now.Add(-time.Hour),
+			AgentTags: []syntropy.AgentTag{{AgentTagName: "prod"}, {AgentTagName: "eu"}}},
+		{AgentId: 2, AgentName: "web-eu-2", AgentPublicIpv4: "10.0.1.1", AgentIsOnline: false, AgentModifiedAt: now.Add(-time.Hour),
+			AgentTags: []syntropy.AgentTag{{AgentTagName: "prod"}, {AgentTagName: "eu"}, {AgentTagName: "canary"}}},
+		{AgentId: 3, AgentName: "db-us-1", AgentPublicIpv4: "192.168.0.1", AgentIsOnline: true, AgentModifiedAt: now.Add(-72 * time.Hour),
+			AgentTags: []syntropy.AgentTag{{AgentTagName: "prod"}, {AgentTagName: "us"}}},
+		{AgentId: 4, AgentName: "dev", AgentPublicIpv4: "", AgentIsOnline: true, AgentModifiedAt: now},
+	}
+	strs := func(v ...string) *[]string { return &v }
+	str := func(v string) *string { return &v }
+	yes := true
+
+	tests := []struct {
+		name   string
+		filter AgentFilter
+		want   []int32
+		empty  bool
+	}{
+		{"no filters", AgentFilter{}, []int32{1, 2, 3, 4}, true},
+		{"tags_all", AgentFilter{TagsAll: strs("prod", "eu")}, []int32{1, 2}, false},
+		{"tags_any", AgentFilter{TagsAny: strs("eu", "us")}, []int32{1, 2, 3}, false},
+		{"tags_none", AgentFilter{TagsAll: strs("prod"), TagsNone: strs("canary")}, []int32{1, 3}, false},
+		{"name_regex", AgentFilter{NameRegex: str("^web-")}, []int32{1, 2}, false},
+		{"public_ip_cidrs", AgentFilter{PublicIPCIDRs: strs("10.0.0.0/24", "192.168.0.0/16")}, []int32{1, 3}, false},
+		{"online_only", AgentFilter{OnlineOnly: &yes}, []int32{1, 3, 4}, false},
+		{"stale_after", AgentFilter{StaleAfter: str("1d")}, []int32{1, 2, 4}, false},
+		{"combined", AgentFilter{TagsAll: strs("prod"), OnlineOnly: &yes, StaleAfter: str("2h")}, []int32{1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newAgentClientFilter(tt.filter, now)
+			if err != nil {
+				t.Fatalf("newAgentClientFilter() returned error: %s", err)
+			}
+			if filter.empty() != tt.empty {
+				t.Errorf("empty() = %v, want %v", filter.empty(), tt.empty)
+			}
+			got := testAgentIDs(filter.applyHealth(filter.apply(agents)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filtered agents = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAgentClientFilterErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter AgentFilter
+	}{
+		{"invalid regex", AgentFilter{NameRegex: func(v string) *string { return &v }("web-[")}},
+		{"invalid cidr", AgentFilter{PublicIPCIDRs: &[]string{"10.0.0.0/33"}}},
+		{"invalid duration", AgentFilter{StaleAfter: func(v string) *string { return &v }("-1d")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newAgentClientFilter(tt.filter, time.Now()); err == nil {
+				t.Error("newAgentClientFilter() returned no error")
+			}
+		})
+	}
+}
+
+func TestWaitForAgentsErrorMessage(t *testing.T) {
+	name := "web-3"
+	tagsAll := []string{"prod", "eu"}
+	ids := []int64{1, 2, 5}
+	agents := []syntropy.V1Agent{
+		{AgentId: 1, AgentName: "web-1", AgentIsOnline: true},
+		{AgentId: 2, AgentName: "web-2", AgentIsOnline: false},
+	}
+
+	tests := []struct {
+		name string
+		data AgentSearchDataSource
+		want string
+	}{
+		{
+			name: "no criteria",
+			data: AgentSearchDataSource{WaitForCount: types.Int64{Value: 3}},
+			want: "Only 2 of 3 expected matching agents found after 5m0s",
+		},
+		{
+			name: "missing name",
+			data: AgentSearchDataSource{WaitForCount: types.Int64{Value: 3}, Filter: &AgentFilter{Name: &name}},
+			want: `Only 2 of 3 expected matching agents found after 5m0s. Missing agents: name="web-3". Search criteria: filter.name="web-3"`,
+		},
+		{
+			name: "missing online ids",
+			data: AgentSearchDataSource{
+				WaitForCount:  types.Int64{Value: 3},
+				WaitForOnline: types.Bool{Value: true},
+				Search:        types.String{Value: "web"},
+				Filter:        &AgentFilter{ID: &ids, TagsAll: &tagsAll},
+			},
+			want: `Only 2 of 3 expected online agents found after 5m0s. Missing agents: id=2, id=5. Search criteria: search="web", filter.tags_all=["prod" "eu"], filter.id=[1 2 5]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := waitForAgentsErrorMessage(tt.data, agents, 2, 5*time.Minute); got != tt.want {
+				t.Errorf("waitForAgentsErrorMessage() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
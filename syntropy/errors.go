@@ -4,4 +4,7 @@ import "errors"
 
 var (
 	ErrConnectionNotFound = errors.New("connection not found")
+	ErrAgentNotFound      = errors.New("agent not found")
+	ErrTooManyAgents      = errors.New("too many agents match search")
+	ErrWaitTimeout        = errors.New("timeout while waiting")
 )
@@ -2,12 +2,29 @@ package syntropy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/SyntropyNet/syntropy-sdk-go/syntropy"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
+const (
+	agentsPageSize      = 100
+	connectionsPageSize = 100
+
+	pollMinInterval = 5 * time.Second
+	pollMaxInterval = 30 * time.Second
+)
+
 func nullableStringToString(s syntropy.NullableString) string {
 	val := s.Get()
 	if val == nil {
@@ -24,6 +41,39 @@ func nullableAgentStatusToString(s syntropy.NullableAgentStatus) string {
 	return val.AgentStatus
 }
 
+func convertAgentToTfValue(agent syntropy.V1Agent) AgentData {
+	return AgentData{
+		ID:              types.Int64{Value: int64(agent.AgentId)},
+		Name:            agent.AgentName,
+		PublicIPv4:      types.String{Value: agent.AgentPublicIpv4},
+		Status:          types.String{Value: nullableAgentStatusToString(agent.AgentStatus)},
+		IsOnline:        types.Bool{Value: agent.AgentIsOnline},
+		Version:         types.String{Value: agent.AgentVersion},
+		LocationCountry: types.String{Value: nullableStringToString(agent.AgentLocationCountry)},
+		LocationCity:    types.String{Value: nullableStringToString(agent.AgentLocationCity)},
+		DeviceID:        types.String{Value: agent.AgentDeviceId},
+		IsVirtual:       types.Bool{Value: agent.AgentIsVirtual},
+		Type:            types.String{Value: string(agent.AgentType)},
+		ModifiedAt:      types.String{Value: agent.AgentModifiedAt.String()},
+		Tags:            convertAgentTagsToTfValue(agent.AgentTags),
+		AgentProvider: &AgentProvider{
+			ID:   int64(agent.AgentProvider.AgentProviderId),
+			Name: agent.AgentProvider.AgentProviderName,
+		},
+	}
+}
+
+func agentNameMatches(name, pattern, mode string) bool {
+	switch mode {
+	case agentNameMatchPrefix:
+		return strings.HasPrefix(name, pattern)
+	case agentNameMatchSubstring:
+		return strings.Contains(name, pattern)
+	default:
+		return name == pattern
+	}
+}
+
 func convertAgentTagsToTfValue(in []syntropy.AgentTag) []Tag {
 	var out []Tag
 	for _, tag := range in {
@@ -35,6 +85,14 @@ func convertAgentTagsToTfValue(in []syntropy.AgentTag) []Tag {
 	return out
 }
 
+func agentTagNames(in []syntropy.AgentTag) []string {
+	var out []string
+	for _, tag := range in {
+		out = append(out, tag.AgentTagName)
+	}
+	return out
+}
+
 func int64ArrayToInt32Array(arr []int64) []int32 {
 	ret := make([]int32, 0, len(arr))
 	for _, v := range arr {
@@ -43,6 +101,86 @@ func int64ArrayToInt32Array(arr []int64) []int32 {
 	return ret
 }
 
+func int64MapToTfValue(in map[string]int64) types.Map {
+	elems := make(map[string]attr.Value, len(in))
+	for key, value := range in {
+		elems[key] = types.Int64{Value: value}
+	}
+	return types.Map{
+		ElemType: types.Int64Type,
+		Elems:    elems,
+	}
+}
+
+func int64MapFromTfValue(ctx context.Context, in types.Map) (map[string]int64, diag.Diagnostics) {
+	out := map[string]int64{}
+	if in.Null || in.Unknown {
+		return out, nil
+	}
+	diags := in.ElementsAs(ctx, &out, false)
+	return out, diags
+}
+
+func int32ArrayToFilter(arr []int32) string {
+	return strings.Trim(strings.Join(strings.Fields(fmt.Sprint(arr)), ","), "[]")
+}
+
+func stringSetsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int, len(a))
+	for _, v := range a {
+		seen[v]++
+	}
+	for _, v := range b {
+		if seen[v] == 0 {
+			return false
+		}
+		seen[v]--
+	}
+	return true
+}
+
+func int64SetsEqual(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[int64]int, len(a))
+	for _, v := range a {
+		seen[v]++
+	}
+	for _, v := range b {
+		if seen[v] == 0 {
+			return false
+		}
+		seen[v]--
+	}
+	return true
+}
+
+// duplicateStrings returns sorted values that appear more than once
+func duplicateStrings(values []string) []string {
+	seen := map[string]int{}
+	var duplicates []string
+	for _, value := range values {
+		seen[value]++
+		if seen[value] == 2 {
+			duplicates = append(duplicates, value)
+		}
+	}
+	sort.Strings(duplicates)
+	return duplicates
+}
+
+// agentPairKey returns key of connection between two agents that doesn't depend on agent order, e.g. "1:2"
+func agentPairKey(agent1ID, agent2ID int64) string {
+	if agent1ID > agent2ID {
+		agent1ID, agent2ID = agent2ID, agent1ID
+	}
+	return fmt.Sprintf("%d:%d", agent1ID, agent2ID)
+}
+
 func stringArrayToAgentTypeArray(arr []string) []syntropy.AgentType {
 	ret := make([]syntropy.AgentType, 0, len(arr))
 	for _, v := range arr {
@@ -60,14 +198,164 @@ func stringArrayToAgentStatusArray(arr []string) []syntropy.AgentFilterAgentStat
 }
 
 func tfValueToDateP(date string) (*time.Time, error) {
-	layout := "2014-09-12T11:45:26.371Z"
-	t, err := time.Parse(layout, date)
+	t, err := parseDateExpression(date, time.Now())
 	if err != nil {
 		return nil, err
 	}
 	return &t, nil
 }
 
+var relativeDurationPartRegexp = regexp.MustCompile(`(\d+(?:\.\d+)?)(ns|us|µs|ms|s|m|h|d|w)`)
+
+// parseDateExpression parses RFC3339 timestamp or duration relative to now. Relative duration must either have
+// explicit sign ("-24h", "+1h30m") or "ago" suffix ("7d ago"). Besides units supported by time.ParseDuration,
+// days (d) and weeks (w) are accepted
+func parseDateExpression(expr string, now time.Time) (time.Time, error) {
+	expr = strings.TrimSpace(expr)
+	if t, err := time.Parse(time.RFC3339, expr); err == nil {
+		return t, nil
+	}
+
+	sign := time.Duration(0)
+	switch {
+	case strings.HasSuffix(expr, " ago"):
+		sign = -1
+		expr = strings.TrimSpace(strings.TrimSuffix(expr, " ago"))
+	case strings.HasPrefix(expr, "-"):
+		sign = -1
+		expr = expr[1:]
+	case strings.HasPrefix(expr, "+"):
+		sign = 1
+		expr = expr[1:]
+	}
+	if sign == 0 {
+		return time.Time{}, fmt.Errorf("%q is neither RFC3339 timestamp nor relative duration with sign or \"ago\" suffix", expr)
+	}
+
+	duration, err := parseRelativeDuration(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(sign * duration), nil
+}
+
+// parseRelativeDuration parses unsigned duration in time.ParseDuration format extended with days (d) and weeks (w)
+func parseRelativeDuration(expr string) (time.Duration, error) {
+	parts := relativeDurationPartRegexp.FindAllStringSubmatchIndex(expr, -1)
+	if len(parts) == 0 || parts[0][0] != 0 || parts[len(parts)-1][1] != len(expr) {
+		return 0, fmt.Errorf("invalid duration %q", expr)
+	}
+
+	var total time.Duration
+	for i, part := range parts {
+		if i > 0 && parts[i-1][1] != part[0] {
+			return 0, fmt.Errorf("invalid duration %q", expr)
+		}
+		value, unit := expr[part[2]:part[3]], expr[part[4]:part[5]]
+		multiplier := 1
+		switch unit {
+		case "d":
+			unit, multiplier = "h", 24
+		case "w":
+			unit, multiplier = "h", 24*7
+		}
+		d, err := time.ParseDuration(value + unit)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", expr, err)
+		}
+		total += d * time.Duration(multiplier)
+	}
+	return total, nil
+}
+
+// compareVersions compares dot separated versions with optional "v" prefix and pre-release suffix, e.g. "v1.10.0-rc1".
+// Numeric parts are compared as numbers, other parts as strings. Release version is greater than its pre-release
+func compareVersions(a, b string) int {
+	a, aPre := splitVersion(a)
+	b, bPre := splitVersion(b)
+
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aPart, bPart := "0", "0"
+		if i < len(aParts) && aParts[i] != "" {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) && bParts[i] != "" {
+			bPart = bParts[i]
+		}
+		if c := compareVersionParts(aPart, bPart); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return strings.Compare(aPre, bPre)
+}
+
+func splitVersion(version string) (string, string) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexByte(version, '+'); i >= 0 {
+		version = version[:i]
+	}
+	if i := strings.IndexByte(version, '-'); i >= 0 {
+		return version[:i], version[i+1:]
+	}
+	return version, ""
+}
+
+func compareVersionParts(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return aNum - bNum
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// pollWithBackoff calls check until it returns true or error. Interval between calls starts at pollMinInterval and is
+// doubled up to pollMaxInterval. ErrWaitTimeout is returned if next call would happen after timeout, and context error
+// if ctx is cancelled while waiting
+func pollWithBackoff(ctx context.Context, timeout time.Duration, check func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	interval := pollMinInterval
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return ErrWaitTimeout
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > pollMaxInterval {
+			interval = pollMaxInterval
+		}
+	}
+}
+
 func sumOfNaturalNumbers(n int) (sum int) {
 	for i := 0; i < n; i++ {
 		sum += i
@@ -75,6 +363,297 @@ func sumOfNaturalNumbers(n int) (sum int) {
 	return sum
 }
 
+func getAgentByID(ctx context.Context, clt syntropy.AgentsApiService, agentID int32) (*syntropy.V1Agent, error) {
+	resp, _, err := clt.V1NetworkAgentsGet(ctx).Filter(fmt.Sprint(agentID)).Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, agent := range resp.Data {
+		if agent.AgentId == agentID {
+			return &agent, nil
+		}
+	}
+	return nil, ErrAgentNotFound
+}
+
+// getAgentsByIDs returns agents with given IDs. Results are paged, so any number of IDs can be requested.
+// Missing agents are skipped
+func getAgentsByIDs(ctx context.Context, clt syntropy.AgentsApiService, agentIDs []int32) ([]syntropy.V1Agent, error) {
+	if len(agentIDs) == 0 {
+		return nil, nil
+	}
+	return searchAllAgents(ctx, clt, syntropy.V1NetworkAgentsSearchRequest{
+		Filter: &syntropy.V1AgentFilter{
+			AgentId: agentIDs,
+		},
+	}, 0)
+}
+
+// searchAllAgents pages through agent search results until all matching agents are fetched.
+// Skip and Take of the request are overridden. If limit is positive and more agents match, ErrTooManyAgents is returned
+func searchAllAgents(ctx context.Context, clt syntropy.AgentsApiService, req syntropy.V1NetworkAgentsSearchRequest, limit int) ([]syntropy.V1Agent, error) {
+	var (
+		agents []syntropy.V1Agent
+		skip   = int32(0)
+		take   = int32(agentsPageSize)
+	)
+
+	for {
+		req.Skip = &skip
+		req.Take = &take
+		resp, _, err := clt.V1NetworkAgentsSearch(ctx).V1NetworkAgentsSearchRequest(req).Execute()
+		if err != nil {
+			return nil, err
+		}
+
+		agents = append(agents, resp.Data...)
+		if limit > 0 && len(agents) > limit {
+			return nil, ErrTooManyAgents
+		}
+		if len(resp.Data) < int(take) {
+			return agents, nil
+		}
+		skip += take
+	}
+}
+
+// searchMatchingAgents pages through agent search results and returns agents accepted by match. Paging stops as soon
+// as limit matching agents are found, so lookups by fields agents API can not filter by do not always scan all agents.
+// Zero limit fetches all pages
+func searchMatchingAgents(ctx context.Context, clt syntropy.AgentsApiService, req syntropy.V1NetworkAgentsSearchRequest, match func(agent syntropy.V1Agent) bool, limit int) ([]syntropy.V1Agent, error) {
+	var (
+		matches []syntropy.V1Agent
+		skip    = int32(0)
+		take    = int32(agentsPageSize)
+	)
+
+	for {
+		req.Skip = &skip
+		req.Take = &take
+		resp, _, err := clt.V1NetworkAgentsSearch(ctx).V1NetworkAgentsSearchRequest(req).Execute()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, agent := range resp.Data {
+			if !match(agent) {
+				continue
+			}
+			matches = append(matches, agent)
+			if limit > 0 && len(matches) >= limit {
+				return matches, nil
+			}
+		}
+		if len(resp.Data) < int(take) {
+			return matches, nil
+		}
+		skip += take
+	}
+}
+
+// resolveAgentNames returns IDs of agents with exactly matching names in the same order as names. Each name is searched
+// separately and search stops after second exact match, which is enough to report name as ambiguous
+func resolveAgentNames(ctx context.Context, clt syntropy.AgentsApiService, names []string) ([]int64, error) {
+	var agents []syntropy.V1Agent
+	for _, name := range names {
+		name := name
+		matches, err := searchMatchingAgents(ctx, clt, syntropy.V1NetworkAgentsSearchRequest{Search: &name}, func(agent syntropy.V1Agent) bool {
+			return agent.AgentName == name
+		}, 2)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, matches...)
+	}
+	return matchAgentNames(agents, names)
+}
+
+// matchAgentNames returns IDs of agents with exactly matching names in the same order as names. All unknown and
+// ambiguous names are reported in a single error
+func matchAgentNames(agents []syntropy.V1Agent, names []string) ([]int64, error) {
+	byName := map[string][]int32{}
+	for _, agent := range agents {
+		byName[agent.AgentName] = append(byName[agent.AgentName], agent.AgentId)
+	}
+
+	var (
+		ids      []int64
+		problems []string
+	)
+	for _, name := range names {
+		matches := byName[name]
+		switch len(matches) {
+		case 0:
+			problems = append(problems, fmt.Sprintf("agent %q not found", name))
+		case 1:
+			ids = append(ids, int64(matches[0]))
+		default:
+			problems = append(problems, fmt.Sprintf("agent name %q is ambiguous, it matches agents %v", name, matches))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return ids, nil
+}
+
+// resolveConfigAgentNames resolves agent names from configuration attribute to agent IDs. Nil IDs are returned if names
+// are not set. True is returned if names are not known yet, e.g. during plan when names come from other resources
+func resolveConfigAgentNames(ctx context.Context, clt syntropy.AgentsApiService, config tfsdk.Config, attributePath path.Path) ([]int64, bool, diag.Diagnostics) {
+	var names types.Set
+	diags := config.GetAttribute(ctx, attributePath, &names)
+	if diags.HasError() || names.Null {
+		return nil, false, diags
+	}
+	if names.Unknown {
+		return nil, true, diags
+	}
+
+	var agentNames []string
+	for _, elem := range names.Elems {
+		name, ok := elem.(types.String)
+		if !ok || name.Unknown {
+			return nil, true, diags
+		}
+		agentNames = append(agentNames, name.Value)
+	}
+
+	ids, err := resolveAgentNames(ctx, clt, agentNames)
+	if err != nil {
+		diags.AddAttributeError(attributePath, "Unable to resolve agent names", err.Error())
+		return nil, false, diags
+	}
+	return ids, false, diags
+}
+
+// searchAllConnections pages through connection search results until all connections matching filter are fetched
+func searchAllConnections(ctx context.Context, clt syntropy.ConnectionsApiService, filter syntropy.V1ConnectionFilter) ([]syntropy.V1Connection, error) {
+	var (
+		connections []syntropy.V1Connection
+		skip        = int32(0)
+		take        = int32(connectionsPageSize)
+	)
+
+	for {
+		resp, _, err := clt.V1NetworkConnectionsSearch(ctx).V1NetworkConnectionsSearchRequest(syntropy.V1NetworkConnectionsSearchRequest{
+			Filter: &filter,
+			Skip:   &skip,
+			Take:   &take,
+		}).Execute()
+		if err != nil {
+			return nil, err
+		}
+
+		connections = append(connections, resp.Data...)
+		if len(resp.Data) < int(take) {
+			return connections, nil
+		}
+		skip += take
+	}
+}
+
+// getConnectionGroupsByAgentIDs returns all connection groups where any of given agents is one of the peers
+func getConnectionGroupsByAgentIDs(ctx context.Context, clt syntropy.ConnectionsApiService, agentIDs []int32) ([]syntropy.V1Connection, error) {
+	return searchAllConnections(ctx, clt, syntropy.V1ConnectionFilter{
+		AgentId: agentIDs,
+	})
+}
+
+// getConnectionGroupsByIDs returns connection groups with given IDs. Missing groups are skipped
+func getConnectionGroupsByIDs(ctx context.Context, clt syntropy.ConnectionsApiService, connectionGroupIDs []int32) ([]syntropy.V1Connection, error) {
+	return searchAllConnections(ctx, clt, syntropy.V1ConnectionFilter{
+		AgentConnectionGroupId: connectionGroupIDs,
+	})
+}
+
+// getConnectionGroupByID returns connection group with given ID or ErrConnectionNotFound
+func getConnectionGroupByID(ctx context.Context, clt syntropy.ConnectionsApiService, connectionGroupID int32) (*syntropy.V1Connection, error) {
+	connections, err := getConnectionGroupsByIDs(ctx, clt, []int32{connectionGroupID})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, connection := range connections {
+		if connection.AgentConnectionGroupId == connectionGroupID {
+			return &connection, nil
+		}
+	}
+	return nil, ErrConnectionNotFound
+}
+
+// getConnectionGroupByAgentPair returns connection group between two agents regardless of which one is agent 1,
+// or ErrConnectionNotFound
+func getConnectionGroupByAgentPair(ctx context.Context, clt syntropy.ConnectionsApiService, agentID1, agentID2 int32) (*syntropy.V1Connection, error) {
+	connections, err := searchAllConnections(ctx, clt, syntropy.V1ConnectionFilter{
+		AgentPair: []syntropy.V1AgentPairFilter{
+			{Agent1Id: agentID1, Agent2Id: agentID2},
+			{Agent1Id: agentID2, Agent2Id: agentID1},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, connection := range connections {
+		if (connection.Agent1.AgentId == agentID1 && connection.Agent2.AgentId == agentID2) || (connection.Agent1.AgentId == agentID2 && connection.Agent2.AgentId == agentID1) {
+			return &connection, nil
+		}
+	}
+	return nil, ErrConnectionNotFound
+}
+
+// getAgentServices returns services of given agents grouped by agent ID
+func getAgentServices(ctx context.Context, clt syntropy.AgentsApiService, agentIDs []int32) (map[int64][]AgentService, error) {
+	resp, _, err := clt.V1NetworkAgentsServicesGet(ctx).Filter(int32ArrayToFilter(agentIDs)).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("error while getting agent services: %w", err)
+	}
+
+	services := map[int64][]AgentService{}
+	for _, service := range resp.Data {
+		var subnets []AgentServiceSubnet
+		for _, subnet := range service.AgentServiceSubnets {
+			subnets = append(subnets, AgentServiceSubnet{
+				ID: int64(subnet.AgentServiceSubnetId),
+				IP: subnet.AgentServiceSubnetIp,
+			})
+		}
+		services[int64(service.AgentId)] = append(services[int64(service.AgentId)], AgentService{
+			ID:      int64(service.AgentServiceId),
+			Name:    service.AgentServiceName,
+			Type:    string(service.AgentServiceType),
+			Subnets: subnets,
+		})
+	}
+	return services, nil
+}
+
+// getAgentConnections returns connections of given agents grouped by agent ID
+func getAgentConnections(ctx context.Context, clt syntropy.ConnectionsApiService, agentIDs []int32) (map[int64][]AgentConnection, error) {
+	groups, err := getConnectionGroupsByAgentIDs(ctx, clt, agentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting agent connections: %w", err)
+	}
+
+	connections := map[int64][]AgentConnection{}
+	for _, group := range groups {
+		agent1, agent2 := int64(group.Agent1.AgentId), int64(group.Agent2.AgentId)
+		connections[agent1] = append(connections[agent1], AgentConnection{
+			ConnectionGroupID: int64(group.AgentConnectionGroupId),
+			PeerAgentID:       agent2,
+			SdnEnabled:        group.AgentConnectionGroupSdnEnabled,
+		})
+		connections[agent2] = append(connections[agent2], AgentConnection{
+			ConnectionGroupID: int64(group.AgentConnectionGroupId),
+			PeerAgentID:       agent1,
+			SdnEnabled:        group.AgentConnectionGroupSdnEnabled,
+		})
+	}
+	return connections, nil
+}
+
 func getOneConnectionDetails(ctx context.Context, clt syntropy.ConnectionsApiService, connectionIDs int32) (*Connection, error) {
 	connections, err := parseConnectionServices(clt.V1NetworkConnectionsServicesGet(ctx), []int32{connectionIDs})
 	if err != nil {
@@ -91,8 +670,7 @@ func getMultipleConnectionDetails(ctx context.Context, clt syntropy.ConnectionsA
 }
 
 func parseConnectionServices(clt syntropy.ApiV1NetworkConnectionsServicesGetRequest, connectionIDs []int32) ([]Connection, error) {
-	connS := strings.Trim(strings.Join(strings.Fields(fmt.Sprint(connectionIDs)), ","), "[]")
-	remote, _, err := clt.Filter(connS).Execute()
+	remote, _, err := clt.Filter(int32ArrayToFilter(connectionIDs)).Execute()
 	if err != nil {
 		return nil, fmt.Errorf("error while getting network connection service: %e", err)
 	}
@@ -0,0 +1,236 @@
+package syntropy
+
+import (
+	"context"
+	"errors"
+	"github.com/SyntropyNet/syntropy-sdk-go/syntropy"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAgentNameMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		mode    string
+		want    bool
+	}{
+		{"web-1", "web-1", agentNameMatchExact, true},
+		{"web-10", "web-1", agentNameMatchExact, false},
+		{"web-10", "web-1", agentNameMatchPrefix, true},
+		{"api-web-1", "web-1", agentNameMatchPrefix, false},
+		{"api-web-1", "web", agentNameMatchSubstring, true},
+		{"api-1", "web", agentNameMatchSubstring, false},
+		{"web-1", "web-1", "", true},
+	}
+	for _, tt := range tests {
+		if got := agentNameMatches(tt.name, tt.pattern, tt.mode); got != tt.want {
+			t.Errorf("agentNameMatches(%q, %q, %q) = %v, want %v", tt.name, tt.pattern, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"1.9", "1.10", -1},
+		{"1.10", "1.9", 1},
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2.0-rc1", "1.2.0", -1},
+		{"1.2.0-rc1", "1.2.0-rc2", -1},
+		{"1.2.0+build5", "1.2.0", 0},
+		{"0.3.12", "0.3.2", 1},
+		{"1.x", "1.1", 1},
+		{"", "0.0.1", -1},
+	}
+	for _, tt := range tests {
+		got := compareVersions(tt.a, tt.b)
+		if (got < 0 && tt.want >= 0) || (got > 0 && tt.want <= 0) || (got == 0 && tt.want != 0) {
+			t.Errorf("compareVersions(%q, %q) = %d, want sign of %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParseDateExpression(t *testing.T) {
+	now := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr    string
+		want    time.Time
+		wantErr bool
+	}{
+		{expr: "2022-07-01T10:00:00Z", want: time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC)},
+		{expr: "2022-07-01T10:00:00+02:00", want: time.Date(2022, 7, 1, 8, 0, 0, 0, time.UTC)},
+		{expr: "-24h", want: now.Add(-24 * time.Hour)},
+		{expr: "+1h30m", want: now.Add(90 * time.Minute)},
+		{expr: "7d ago", want: now.Add(-7 * 24 * time.Hour)},
+		{expr: " 2w ago ", want: now.Add(-14 * 24 * time.Hour)},
+		{expr: "24h", wantErr: true},
+		{expr: "yesterday", wantErr: true},
+		{expr: "-1y", wantErr: true},
+		{expr: "2022-07-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDateExpression(tt.expr, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseDateExpression(%q) = %s, want error", tt.expr, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseDateExpression(%q) returned error: %s", tt.expr, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseDateExpression(%q) = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseRelativeDuration(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{expr: "30s", want: 30 * time.Second},
+		{expr: "1h30m", want: 90 * time.Minute},
+		{expr: "1.5h", want: 90 * time.Minute},
+		{expr: "2d", want: 48 * time.Hour},
+		{expr: "1w2d", want: 9 * 24 * time.Hour},
+		{expr: "500ms", want: 500 * time.Millisecond},
+		{expr: "", wantErr: true},
+		{expr: "10", wantErr: true},
+		{expr: "-1h", wantErr: true},
+		{expr: "1h 30m", wantErr: true},
+		{expr: "1y", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseRelativeDuration(tt.expr)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseRelativeDuration(%q) = %s, want error", tt.expr, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRelativeDuration(%q) returned error: %s", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseRelativeDuration(%q) = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestPollWithBackoff(t *testing.T) {
+	checkErr := errors.New("check failed")
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		timeout time.Duration
+		results []bool
+		err     error
+		want    error
+		calls   int
+	}{
+		{"done immediately", context.Background(), time.Minute, []bool{true}, nil, nil, 1},
+		{"check error", context.Background(), time.Minute, []bool{false}, checkErr, checkErr, 1},
+		{"timeout before next call", context.Background(), time.Second, []bool{false}, nil, ErrWaitTimeout, 1},
+		{"cancelled while waiting", cancelled, time.Minute, []bool{false}, nil, context.Canceled, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := pollWithBackoff(tt.ctx, tt.timeout, func() (bool, error) {
+				calls++
+				return tt.results[calls-1], tt.err
+			})
+			if err != tt.want {
+				t.Errorf("pollWithBackoff() = %v, want %v", err, tt.want)
+			}
+			if calls != tt.calls {
+				t.Errorf("check called %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestAgentPairKey(t *testing.T) {
+	tests := []struct {
+		agent1ID int64
+		agent2ID int64
+		want     string
+	}{
+		{1, 2, "1:2"},
+		{2, 1, "1:2"},
+		{10, 9, "9:10"},
+		{5, 5, "5:5"},
+	}
+	for _, tt := range tests {
+		if got := agentPairKey(tt.agent1ID, tt.agent2ID); got != tt.want {
+			t.Errorf("agentPairKey(%d, %d) = %s, want %s", tt.agent1ID, tt.agent2ID, got, tt.want)
+		}
+	}
+}
+
+func TestDuplicateStrings(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   []string
+	}{
+		{nil, nil},
+		{[]string{"1:2", "1:3"}, nil},
+		{[]string{"1:3", "1:2", "1:3", "1:2", "1:2"}, []string{"1:2", "1:3"}},
+	}
+	for _, tt := range tests {
+		if got := duplicateStrings(tt.values); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("duplicateStrings(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestMatchAgentNames(t *testing.T) {
+	agents := []syntropy.V1Agent{
+		{AgentId: 1, AgentName: "web-1"},
+		{AgentId: 2, AgentName: "web-10"},
+		{AgentId: 3, AgentName: "db"},
+		{AgentId: 4, AgentName: "db"},
+	}
+	tests := []struct {
+		name    string
+		names   []string
+		want    []int64
+		wantErr string
+	}{
+		{"exact match only", []string{"web-1"}, []int64{1}, ""},
+		{"keeps name order", []string{"web-10", "web-1"}, []int64{2, 1}, ""},
+		{"unknown and ambiguous", []string{"web", "db", "web-1"}, nil, `agent "web" not found; agent name "db" is ambiguous, it matches agents [3 4]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchAgentNames(agents, tt.names)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("matchAgentNames() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("matchAgentNames() returned error: %s", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchAgentNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ type Connection struct {
 type NetworkConnectionMeshEdit struct {
 	ID          types.String `tfsdk:"id"`
 	AgentIds    []int32      `tfsdk:"agent_ids"`
+	AgentNames  []string     `tfsdk:"agent_names"`
 	Connections types.Set    `tfsdk:"connections"`
 	SdnEnabled  types.Bool   `tfsdk:"sdn_enabled"`
 }
@@ -19,47 +20,192 @@ type NetworkConnectionMeshEdit struct {
 type NetworkConnectionMesh struct {
 	ID          types.String `tfsdk:"id"`
 	AgentIds    []int32      `tfsdk:"agent_ids"`
+	AgentNames  []string     `tfsdk:"agent_names"`
 	Connections []Connection `tfsdk:"connections"`
 	SdnEnabled  types.Bool   `tfsdk:"sdn_enabled"`
 }
 
 type NetworkConnection struct {
-	ID         types.Int64             `tfsdk:"id"`
-	AgentIds   []int64                 `tfsdk:"agent_peer"`
-	SdnEnabled types.Bool              `tfsdk:"sdn_enabled"`
-	Services   []ConnectionServiceData `tfsdk:"services"`
+	ID              types.Int64                `tfsdk:"id"`
+	AgentIds        []int64                    `tfsdk:"agent_peer"`
+	AgentPeerNames  []string                   `tfsdk:"agent_peer_names"`
+	Agent1ID        types.Int64                `tfsdk:"agent_1_id"`
+	Agent2ID        types.Int64                `tfsdk:"agent_2_id"`
+	SdnEnabled      types.Bool                 `tfsdk:"sdn_enabled"`
+	AdoptExisting   types.Bool                 `tfsdk:"adopt_existing"`
+	WaitForServices *ConnectionServicesWait    `tfsdk:"wait_for_services"`
+	EnableServices  *ConnectionServiceSelector `tfsdk:"enable_services"`
+	Status          types.String               `tfsdk:"status"`
+	StatusReason    types.String               `tfsdk:"status_reason"`
+	LatencyMs       types.Float64              `tfsdk:"latency_ms"`
+	PacketLoss      types.Float64              `tfsdk:"packet_loss"`
+	SdnActive       types.Bool                 `tfsdk:"sdn_active"`
+	Services        []ConnectionServiceData    `tfsdk:"services"`
+}
+
+type ConnectionServicesWait struct {
+	MinCount types.Int64  `tfsdk:"min_count"`
+	Names    []string     `tfsdk:"names"`
+	Timeout  types.String `tfsdk:"timeout"`
+}
+
+type ConnectionServiceSelector struct {
+	NameRegex types.String `tfsdk:"name_regex"`
+	Type      types.String `tfsdk:"type"`
+	AgentSide types.String `tfsdk:"agent_side"`
+}
+
+type NetworkConnectionsResource struct {
+	ID                 types.String             `tfsdk:"id"`
+	Connections        []NetworkConnectionsPair `tfsdk:"connections"`
+	ConnectionGroupIDs types.Map                `tfsdk:"connection_group_ids"`
+}
+
+type NetworkConnectionsPair struct {
+	Agent1ID   int64      `tfsdk:"agent_1_id"`
+	Agent2ID   int64      `tfsdk:"agent_2_id"`
+	SdnEnabled types.Bool `tfsdk:"sdn_enabled"`
+}
+
+type ConnectionReadyResource struct {
+	ID                 types.String      `tfsdk:"id"`
+	ConnectionGroupIDs []int64           `tfsdk:"connection_group_ids"`
+	Timeout            types.String      `tfsdk:"timeout"`
+	Statuses           map[string]string `tfsdk:"statuses"`
 }
 
 type AgentResource struct {
-	ID    types.Int64  `tfsdk:"id"`
-	Name  types.String `tfsdk:"name"`
-	Token types.String `tfsdk:"token"`
-	Tags  []string     `tfsdk:"tags"`
+	ID                 types.Int64  `tfsdk:"id"`
+	Name               types.String `tfsdk:"name"`
+	Token              types.String `tfsdk:"token"`
+	Tags               []string     `tfsdk:"tags"`
+	AdoptExisting      types.Bool   `tfsdk:"adopt_existing"`
+	AdoptDeviceID      types.String `tfsdk:"adopt_device_id"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
+	ForceDetach        types.Bool   `tfsdk:"force_detach"`
+}
+
+type AgentFleetResource struct {
+	ID       types.String                `tfsdk:"id"`
+	Agents   map[string]AgentFleetMember `tfsdk:"agents"`
+	AgentIDs types.Map                   `tfsdk:"agent_ids"`
+}
+
+type AgentFleetMember struct {
+	Name  string   `tfsdk:"name"`
+	Token string   `tfsdk:"token"`
+	Tags  []string `tfsdk:"tags"`
+}
+
+type AgentSettingsResource struct {
+	ID               types.Int64  `tfsdk:"id"`
+	AgentID          types.Int64  `tfsdk:"agent_id"`
+	Name             types.String `tfsdk:"name"`
+	Tags             []string     `tfsdk:"tags"`
+	RestoreOnDestroy types.Bool   `tfsdk:"restore_on_destroy"`
+	OriginalName     types.String `tfsdk:"original_name"`
+	OriginalTags     []string     `tfsdk:"original_tags"`
+}
+
+type AgentInstallConfigDataSource struct {
+	ID                  types.String `tfsdk:"id"`
+	Token               string       `tfsdk:"token"`
+	Name                string       `tfsdk:"name"`
+	Tags                []string     `tfsdk:"tags"`
+	ApiURL              types.String `tfsdk:"api_url"`
+	NetworkMode         types.String `tfsdk:"network_mode"`
+	Image               types.String `tfsdk:"image"`
+	DockerCompose       types.String `tfsdk:"docker_compose"`
+	DockerRun           types.String `tfsdk:"docker_run"`
+	CloudInit           types.String `tfsdk:"cloud_init"`
+	SystemdUnit         types.String `tfsdk:"systemd_unit"`
+	KubernetesDaemonSet types.String `tfsdk:"kubernetes_daemonset"`
 }
 
 type AgentSearchDataSource struct {
-	Skip   types.Int64  `tfsdk:"skip"`
-	Take   types.Int64  `tfsdk:"take"`
-	Search types.String `tfsdk:"search"`
-	Filter *AgentFilter `tfsdk:"filter"`
-	Agents []AgentData  `tfsdk:"agents"`
+	Skip               types.Int64          `tfsdk:"skip"`
+	Take               types.Int64          `tfsdk:"take"`
+	MaxResults         types.Int64          `tfsdk:"max_results"`
+	TotalCount         types.Int64          `tfsdk:"total_count"`
+	Search             types.String         `tfsdk:"search"`
+	Order              *AgentOrder          `tfsdk:"order"`
+	IncludeServices    types.Bool           `tfsdk:"include_services"`
+	IncludeConnections types.Bool           `tfsdk:"include_connections"`
+	WaitForCount       types.Int64          `tfsdk:"wait_for_count"`
+	WaitForOnline      types.Bool           `tfsdk:"wait_for_online"`
+	WaitTimeout        types.String         `tfsdk:"wait_timeout"`
+	Filter             *AgentFilter         `tfsdk:"filter"`
+	Agents             []AgentData          `tfsdk:"agents"`
+	IDs                []int64              `tfsdk:"ids"`
+	OfflineIDs         []int64              `tfsdk:"offline_ids"`
+	Names              []string             `tfsdk:"names"`
+	ByName             map[string]AgentData `tfsdk:"by_name"`
+	ByTag              map[string][]int64   `tfsdk:"by_tag"`
+	ByCountry          map[string][]int64   `tfsdk:"by_country"`
+}
+
+type AgentOrder struct {
+	Field     types.String `tfsdk:"field"`
+	Direction types.String `tfsdk:"direction"`
+}
+
+type AgentDataSource struct {
+	ID                 types.Int64       `tfsdk:"id"`
+	Name               types.String      `tfsdk:"name"`
+	Match              types.String      `tfsdk:"match"`
+	IncludeServices    types.Bool        `tfsdk:"include_services"`
+	IncludeConnections types.Bool        `tfsdk:"include_connections"`
+	PublicIPv4         types.String      `tfsdk:"public_ipv4"`
+	Status             types.String      `tfsdk:"status"`
+	IsOnline           types.Bool        `tfsdk:"is_online"`
+	Version            types.String      `tfsdk:"version"`
+	LocationCountry    types.String      `tfsdk:"location_country"`
+	LocationCity       types.String      `tfsdk:"location_city"`
+	DeviceID           types.String      `tfsdk:"device_id"`
+	IsVirtual          types.Bool        `tfsdk:"is_virtual"`
+	Type               types.String      `tfsdk:"type"`
+	ModifiedAt         types.String      `tfsdk:"modified_at"`
+	Tags               []Tag             `tfsdk:"tags"`
+	AgentProvider      *AgentProvider    `tfsdk:"provider"`
+	Services           []AgentService    `tfsdk:"services"`
+	Connections        []AgentConnection `tfsdk:"connections"`
 }
 
 type AgentData struct {
-	ID              types.Int64    `tfsdk:"id"`
-	Name            string         `tfsdk:"name"`
-	PublicIPv4      types.String   `tfsdk:"public_ipv4"`
-	Status          types.String   `tfsdk:"status"`
-	IsOnline        types.Bool     `tfsdk:"is_online"`
-	Version         types.String   `tfsdk:"version"`
-	LocationCountry types.String   `tfsdk:"location_country"`
-	LocationCity    types.String   `tfsdk:"location_city"`
-	DeviceID        types.String   `tfsdk:"device_id"`
-	IsVirtual       types.Bool     `tfsdk:"is_virtual"`
-	Type            types.String   `tfsdk:"type"`
-	ModifiedAt      types.String   `tfsdk:"modified_at"`
-	Tags            []Tag          `tfsdk:"tags"`
-	AgentProvider   *AgentProvider `tfsdk:"provider"`
+	ID              types.Int64       `tfsdk:"id"`
+	Name            string            `tfsdk:"name"`
+	PublicIPv4      types.String      `tfsdk:"public_ipv4"`
+	Status          types.String      `tfsdk:"status"`
+	IsOnline        types.Bool        `tfsdk:"is_online"`
+	Version         types.String      `tfsdk:"version"`
+	LocationCountry types.String      `tfsdk:"location_country"`
+	LocationCity    types.String      `tfsdk:"location_city"`
+	DeviceID        types.String      `tfsdk:"device_id"`
+	IsVirtual       types.Bool        `tfsdk:"is_virtual"`
+	Type            types.String      `tfsdk:"type"`
+	ModifiedAt      types.String      `tfsdk:"modified_at"`
+	Tags            []Tag             `tfsdk:"tags"`
+	AgentProvider   *AgentProvider    `tfsdk:"provider"`
+	Services        []AgentService    `tfsdk:"services"`
+	Connections     []AgentConnection `tfsdk:"connections"`
+}
+
+type AgentService struct {
+	ID      int64                `tfsdk:"id"`
+	Name    string               `tfsdk:"name"`
+	Type    string               `tfsdk:"type"`
+	Subnets []AgentServiceSubnet `tfsdk:"subnets"`
+}
+
+type AgentServiceSubnet struct {
+	ID int64  `tfsdk:"id"`
+	IP string `tfsdk:"ip"`
+}
+
+type AgentConnection struct {
+	ConnectionGroupID int64 `tfsdk:"connection_group_id"`
+	PeerAgentID       int64 `tfsdk:"peer_agent_id"`
+	SdnEnabled        bool  `tfsdk:"sdn_enabled"`
 }
 
 type Tag struct {
@@ -80,10 +226,17 @@ type AgentFilter struct {
 	Type            *[]string `tfsdk:"type"`
 	Version         *[]string `tfsdk:"version"`
 	TagName         *[]string `tfsdk:"tag_name"`
+	TagsAll         *[]string `tfsdk:"tags_all"`
+	TagsAny         *[]string `tfsdk:"tags_any"`
+	TagsNone        *[]string `tfsdk:"tags_none"`
 	Status          *[]string `tfsdk:"status"`
 	LocationCountry *[]string `tfsdk:"location_country"`
 	ModifiedAtFrom  *string   `tfsdk:"modified_at_from"`
 	ModifiedAtTo    *string   `tfsdk:"modified_at_to"`
+	NameRegex       *string   `tfsdk:"name_regex"`
+	PublicIPCIDRs   *[]string `tfsdk:"public_ip_cidrs"`
+	OnlineOnly      *bool     `tfsdk:"online_only"`
+	StaleAfter      *string   `tfsdk:"stale_after"`
 }
 
 type NetworkConnectionServiceDataSource struct {
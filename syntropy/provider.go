@@ -113,8 +113,12 @@ func (p *provider) GetResources(ctx context.Context) (map[string]tfsdk.ResourceT
 	return map[string]tfsdk.ResourceType{
 		"syntropystack_network_connection_mesh":     networkConnectionMeshResourceType{},
 		"syntropystack_network_connection":          networkConnectionResourceType{},
+		"syntropystack_network_connections":         networkConnectionsResourceType{},
 		"syntropystack_network_connection_services": networkConnectionServiceResourceType{},
+		"syntropystack_connection_ready":            connectionReadyResourceType{},
 		"syntropystack_agent":                       agentResourceType{},
+		"syntropystack_agent_fleet":                 agentFleetResourceType{},
+		"syntropystack_agent_settings":              agentSettingsResourceType{},
 	}, nil
 }
 
@@ -122,6 +126,7 @@ func (p *provider) GetDataSources(ctx context.Context) (map[string]tfsdk.DataSou
 	return map[string]tfsdk.DataSourceType{
 		"syntropystack_agent":                       agentDataSourceType{},
 		"syntropystack_agent_search":                agentSearchDataSourceType{},
+		"syntropystack_agent_install_config":        agentInstallConfigDataSourceType{},
 		"syntropystack_network_connection_services": networkConnectionServiceDataSourceType{},
 	}, nil
 }
@@ -56,6 +56,26 @@ func (t agentResourceType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Di
 					ElemType: types.StringType,
 				},
 			},
+			"adopt_existing": {
+				Description: "Takes over existing agent with the same name instead of creating a new one. Used to recover from interrupted applies",
+				Optional:    true,
+				Type:        types.BoolType,
+			},
+			"adopt_device_id": {
+				Description: "Device ID used to look up existing agent when adopt_existing is enabled. If not set, agent is looked up by name",
+				Optional:    true,
+				Type:        types.StringType,
+			},
+			"deletion_protection": {
+				Description: "Prevents agent from being deleted. Must be set to false and applied before agent can be destroyed",
+				Optional:    true,
+				Type:        types.BoolType,
+			},
+			"force_detach": {
+				Description: "Removes agent network connections before deleting agent. If not set, agent is deleted without touching its connections",
+				Optional:    true,
+				Type:        types.BoolType,
+			},
 		},
 	}, nil
 }
@@ -76,6 +96,30 @@ func (r agentResource) Create(ctx context.Context, req tfsdk.CreateResourceReque
 		return
 	}
 
+	if plan.AdoptExisting.Value {
+		existing, err := r.findExistingAgent(ctx, plan)
+		if err != nil {
+			resp.Diagnostics.AddError("Error while looking up existing virtual agent", err.Error())
+			return
+		}
+
+		if existing != nil {
+			_, err = r.provider.client.AgentsApi.V1NetworkAgentsUpdate(ctx, existing.AgentId).V1NetworkAgentsUpdateRequest(syntropy.V1NetworkAgentsUpdateRequest{
+				AgentTags: plan.Tags,
+				AgentName: &plan.Name.Value,
+			}).Execute()
+			if err != nil {
+				resp.Diagnostics.AddError(fmt.Sprintf("Error while adopting existing virtual agent %d", existing.AgentId), err.Error())
+				return
+			}
+
+			plan.ID = types.Int64{Value: int64(existing.AgentId)}
+			diags = resp.State.Set(ctx, &plan)
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+	}
+
 	agent, _, err := r.provider.client.AgentsApi.V1NetworkAgentsCreate(ctx).V1NetworkAgentsCreateRequest(syntropy.V1NetworkAgentsCreateRequest{
 		AgentName:  plan.Name.Value,
 		AgentToken: plan.Token.Value,
@@ -160,6 +204,42 @@ func (r agentResource) Delete(ctx context.Context, req tfsdk.DeleteResourceReque
 		return
 	}
 
+	if data.DeletionProtection.Value {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("Agent %d is protected from deletion", data.ID.Value),
+			"Set deletion_protection to false and apply the change before destroying this agent",
+		)
+		return
+	}
+
+	// Without force_detach agent is deleted as before and platform decides what happens with its connections
+	if data.ForceDetach.Value {
+		connections, err := getConnectionGroupsByAgentIDs(ctx, *r.provider.client.ConnectionsApi, []int32{int32(data.ID.Value)})
+		if err != nil {
+			resp.Diagnostics.AddError("Error while getting virtual agent connections", err.Error())
+			return
+		}
+
+		if len(connections) > 0 {
+			var connectionIDs []int32
+			for _, connection := range connections {
+				connectionIDs = append(connectionIDs, connection.AgentConnectionGroupId)
+			}
+
+			_, err = r.provider.client.ConnectionsApi.V1NetworkConnectionsRemove(ctx).V1NetworkConnectionsRemoveRequest(syntropy.V1NetworkConnectionsRemoveRequest{
+				AgentConnectionGroupIds: connectionIDs,
+			}).Execute()
+			if err != nil {
+				resp.Diagnostics.AddError("Error while detaching virtual agent connections", err.Error())
+				return
+			}
+			resp.Diagnostics.AddWarning(
+				fmt.Sprintf("Removed agent %d network connections", data.ID.Value),
+				fmt.Sprintf("Connection groups removed before deleting agent: %v", connectionIDs),
+			)
+		}
+	}
+
 	_, err := r.provider.client.AgentsApi.V1NetworkAgentsRemove(ctx).V1NetworkAgentsRemoveRequest(syntropy.V1NetworkAgentsRemoveRequest{
 		AgentIds: []int32{int32(data.ID.Value)},
 	}).Execute()
@@ -169,6 +249,48 @@ func (r agentResource) Delete(ctx context.Context, req tfsdk.DeleteResourceReque
 	}
 }
 
+// findExistingAgent looks up agent by device ID or exact name. Returns nil if no agent matches
+func (r agentResource) findExistingAgent(ctx context.Context, plan AgentResource) (*syntropy.V1Agent, error) {
+	var (
+		search *string
+		match  func(agent syntropy.V1Agent) bool
+		limit  int
+	)
+
+	lookup := plan.Name.Value
+	if !plan.AdoptDeviceID.Null && plan.AdoptDeviceID.Value != "" {
+		// Agents API can not filter by device ID. Device ID is unique, so search stops at the first match
+		lookup = plan.AdoptDeviceID.Value
+		limit = 1
+		match = func(agent syntropy.V1Agent) bool {
+			return agent.AgentDeviceId == lookup
+		}
+	} else {
+		search = &plan.Name.Value
+		match = func(agent syntropy.V1Agent) bool {
+			return agent.AgentName == lookup
+		}
+	}
+
+	matches, err := searchMatchingAgents(ctx, *r.provider.client.AgentsApi, syntropy.V1NetworkAgentsSearchRequest{Search: search}, match, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &matches[0], nil
+	default:
+		var ids []int32
+		for _, agent := range matches {
+			ids = append(ids, agent.AgentId)
+		}
+		return nil, fmt.Errorf("found %d agents %v matching %q, unable to choose which one to adopt", len(matches), ids, lookup)
+	}
+}
+
 func (r agentResource) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
 	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
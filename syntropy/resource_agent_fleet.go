@@ -0,0 +1,305 @@
+package syntropy
+
+import (
+	"context"
+	"fmt"
+	"github.com/SyntropyNet/syntropy-sdk-go/syntropy"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"sort"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ tfsdk.ResourceType = agentFleetResourceType{}
+var _ tfsdk.Resource = agentFleetResource{}
+
+type agentFleetResourceType struct{}
+
+type agentFleetResource struct {
+	provider provider
+}
+
+func (t agentFleetResourceType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		Description: "Creates and manages a fleet of virtual Syntropy platform agents",
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Description: "Agent fleet ID randomly generated",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"agents": {
+				Description: "Map of fleet member key to virtual agent definition",
+				Required:    true,
+				Validators: []tfsdk.AttributeValidator{
+					mapvalidator.SizeAtLeast(1),
+				},
+				Attributes: tfsdk.MapNestedAttributes(map[string]tfsdk.Attribute{
+					"name": {
+						Description: "Agent name",
+						Type:        types.StringType,
+						Required:    true,
+					},
+					"token": {
+						Description: "Agent token. Changing token recreates fleet member",
+						Type:        types.StringType,
+						Required:    true,
+						Sensitive:   true,
+					},
+					"tags": {
+						Description: "Agent tags",
+						Optional:    true,
+						Type: types.SetType{
+							ElemType: types.StringType,
+						},
+					},
+				}),
+			},
+			"agent_ids": {
+				Description: "Map of fleet member key to created agent ID",
+				Computed:    true,
+				Type: types.MapType{
+					ElemType: types.Int64Type,
+				},
+			},
+		},
+	}, nil
+}
+
+func (t agentFleetResourceType) NewResource(ctx context.Context, in tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	provider, diags := convertProviderType(in)
+	return agentFleetResource{
+		provider: provider,
+	}, diags
+}
+
+func (r agentFleetResource) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	var plan AgentFleetResource
+	ctx = r.provider.createAuthContext(ctx)
+	diags := req.Config.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.String{Value: uuid.New().String()}
+	agentIDs := map[string]int64{}
+	created := map[string]AgentFleetMember{}
+
+	for _, key := range sortedFleetKeys(plan.Agents) {
+		member := plan.Agents[key]
+		agentID, err := r.createMember(ctx, member)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Error while creating fleet agent %q", key), err.Error())
+			break
+		}
+		agentIDs[key] = agentID
+		created[key] = member
+	}
+
+	// Members created before a failure are saved to state, so they are not duplicated on the next apply
+	plan.Agents = created
+	plan.AgentIDs = int64MapToTfValue(agentIDs)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r agentFleetResource) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	var state AgentFleetResource
+	ctx = r.provider.createAuthContext(ctx)
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	agentIDs, diags := int64MapFromTfValue(ctx, state.AgentIDs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var ids []int32
+	for _, id := range agentIDs {
+		ids = append(ids, int32(id))
+	}
+
+	agents, err := getAgentsByIDs(ctx, *r.provider.client.AgentsApi, ids)
+	if err != nil {
+		resp.Diagnostics.AddError("Error while getting fleet agents", err.Error())
+		return
+	}
+
+	remoteAgents := map[int32]syntropy.V1Agent{}
+	for _, agent := range agents {
+		remoteAgents[agent.AgentId] = agent
+	}
+
+	// Members removed outside terraform are dropped from state, so they are created again on the next apply
+	members := map[string]AgentFleetMember{}
+	for key, id := range agentIDs {
+		agent, ok := remoteAgents[int32(id)]
+		if !ok {
+			delete(agentIDs, key)
+			continue
+		}
+
+		member := state.Agents[key]
+		member.Name = agent.AgentName
+		member.Tags = agentTagNames(agent.AgentTags)
+		members[key] = member
+	}
+
+	if len(members) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Agents = members
+	state.AgentIDs = int64MapToTfValue(agentIDs)
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r agentFleetResource) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	var plan, state AgentFleetResource
+	ctx = r.provider.createAuthContext(ctx)
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	agentIDs, diags := int64MapFromTfValue(ctx, state.AgentIDs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Remove members that are not in plan anymore or have changed token in a single call
+	removeRequest := syntropy.V1NetworkAgentsRemoveRequest{}
+	members := map[string]AgentFleetMember{}
+	for _, key := range sortedFleetKeys(state.Agents) {
+		planMember, ok := plan.Agents[key]
+		if ok && planMember.Token == state.Agents[key].Token {
+			members[key] = state.Agents[key]
+			continue
+		}
+		if id, ok := agentIDs[key]; ok {
+			removeRequest.AgentIds = append(removeRequest.AgentIds, int32(id))
+			delete(agentIDs, key)
+		}
+	}
+
+	if len(removeRequest.AgentIds) > 0 {
+		_, err := r.provider.client.AgentsApi.V1NetworkAgentsRemove(ctx).V1NetworkAgentsRemoveRequest(removeRequest).Execute()
+		if err != nil {
+			resp.Diagnostics.AddError("Error while deleting fleet agents", err.Error())
+			return
+		}
+	}
+
+	for _, key := range sortedFleetKeys(plan.Agents) {
+		planMember := plan.Agents[key]
+		stateMember, exists := members[key]
+		if !exists {
+			agentID, err := r.createMember(ctx, planMember)
+			if err != nil {
+				resp.Diagnostics.AddError(fmt.Sprintf("Error while creating fleet agent %q", key), err.Error())
+				break
+			}
+			agentIDs[key] = agentID
+			members[key] = planMember
+			continue
+		}
+
+		if stateMember.Name == planMember.Name && stringSetsEqual(stateMember.Tags, planMember.Tags) {
+			continue
+		}
+
+		_, err := r.provider.client.AgentsApi.V1NetworkAgentsUpdate(ctx, int32(agentIDs[key])).V1NetworkAgentsUpdateRequest(syntropy.V1NetworkAgentsUpdateRequest{
+			AgentTags: planMember.Tags,
+			AgentName: &planMember.Name,
+		}).Execute()
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Error while updating fleet agent %q", key), err.Error())
+			break
+		}
+		members[key] = planMember
+	}
+
+	plan.Agents = members
+	plan.AgentIDs = int64MapToTfValue(agentIDs)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r agentFleetResource) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	var data AgentFleetResource
+	ctx = r.provider.createAuthContext(ctx)
+	diags := req.State.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	agentIDs, diags := int64MapFromTfValue(ctx, data.AgentIDs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	removeRequest := syntropy.V1NetworkAgentsRemoveRequest{}
+	for _, id := range agentIDs {
+		removeRequest.AgentIds = append(removeRequest.AgentIds, int32(id))
+	}
+
+	if len(removeRequest.AgentIds) == 0 {
+		return
+	}
+
+	_, err := r.provider.client.AgentsApi.V1NetworkAgentsRemove(ctx).V1NetworkAgentsRemoveRequest(removeRequest).Execute()
+	if err != nil {
+		resp.Diagnostics.AddError("Error while deleting fleet agents", err.Error())
+		return
+	}
+}
+
+func (r agentFleetResource) createMember(ctx context.Context, member AgentFleetMember) (int64, error) {
+	agent, _, err := r.provider.client.AgentsApi.V1NetworkAgentsCreate(ctx).V1NetworkAgentsCreateRequest(syntropy.V1NetworkAgentsCreateRequest{
+		AgentName:  member.Name,
+		AgentToken: member.Token,
+		AgentTags:  member.Tags,
+	}).Execute()
+	if err != nil {
+		return 0, err
+	}
+	return int64(agent.Data.AgentId), nil
+}
+
+func sortedFleetKeys(members map[string]AgentFleetMember) []string {
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
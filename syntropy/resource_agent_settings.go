@@ -0,0 +1,241 @@
+package syntropy
+
+import (
+	"context"
+	"fmt"
+	"github.com/SyntropyNet/syntropy-sdk-go/syntropy"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strconv"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ tfsdk.ResourceType = agentSettingsResourceType{}
+var _ tfsdk.Resource = agentSettingsResource{}
+var _ tfsdk.ResourceWithImportState = agentSettingsResource{}
+
+type agentSettingsResourceType struct{}
+
+type agentSettingsResource struct {
+	provider provider
+}
+
+func (t agentSettingsResourceType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		Description: "Manages name and tags of existing Syntropy platform agent. Agent itself is never created or deleted",
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Description: "Agent ID",
+				Type:        types.Int64Type,
+				Computed:    true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"agent_id": {
+				Description: "ID of existing agent to manage",
+				Type:        types.Int64Type,
+				Required:    true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"name": {
+				Description: "Agent name. If not set, current agent name is kept",
+				Type:        types.StringType,
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"tags": {
+				Description: "Agent tags. If not set, current agent tags are kept",
+				Optional:    true,
+				Type: types.SetType{
+					ElemType: types.StringType,
+				},
+			},
+			"restore_on_destroy": {
+				Description: "Should original agent name and tags be restored when resource is destroyed?",
+				Optional:    true,
+				Type:        types.BoolType,
+			},
+			"original_name": {
+				Description: "Agent name before it was managed by terraform",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"original_tags": {
+				Description: "Agent tags before they were managed by terraform",
+				Computed:    true,
+				Type: types.SetType{
+					ElemType: types.StringType,
+				},
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+		},
+	}, nil
+}
+
+func (t agentSettingsResourceType) NewResource(ctx context.Context, in tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	provider, diags := convertProviderType(in)
+	return agentSettingsResource{
+		provider: provider,
+	}, diags
+}
+
+func (r agentSettingsResource) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	var plan AgentSettingsResource
+	ctx = r.provider.createAuthContext(ctx)
+	diags := req.Config.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	agent, err := getAgentByID(ctx, *r.provider.client.AgentsApi, int32(plan.AgentID.Value))
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Error while getting agent %d", plan.AgentID.Value), err.Error())
+		return
+	}
+
+	originalTags := agentTagNames(agent.AgentTags)
+	plan.ID = types.Int64{Value: int64(agent.AgentId)}
+	plan.OriginalName = types.String{Value: agent.AgentName}
+	plan.OriginalTags = originalTags
+	if plan.Name.Null {
+		plan.Name = types.String{Value: agent.AgentName}
+	}
+
+	tags := plan.Tags
+	if tags == nil {
+		tags = originalTags
+	}
+
+	_, err = r.provider.client.AgentsApi.V1NetworkAgentsUpdate(ctx, agent.AgentId).V1NetworkAgentsUpdateRequest(syntropy.V1NetworkAgentsUpdateRequest{
+		AgentTags: tags,
+		AgentName: &plan.Name.Value,
+	}).Execute()
+	if err != nil {
+		resp.Diagnostics.AddError("Error while updating agent settings", err.Error())
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r agentSettingsResource) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	var state AgentSettingsResource
+	ctx = r.provider.createAuthContext(ctx)
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	agent, err := getAgentByID(ctx, *r.provider.client.AgentsApi, int32(state.AgentID.Value))
+	if err != nil {
+		if err == ErrAgentNotFound {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(fmt.Sprintf("Error while getting agent %d", state.AgentID.Value), err.Error())
+		return
+	}
+
+	tags := agentTagNames(agent.AgentTags)
+	// Imported resource has no original values yet, so current agent settings are treated as original ones
+	if state.OriginalName.Null {
+		state.OriginalName = types.String{Value: agent.AgentName}
+		state.OriginalTags = tags
+	}
+
+	state.ID = types.Int64{Value: int64(agent.AgentId)}
+	state.Name = types.String{Value: agent.AgentName}
+	if state.Tags != nil {
+		state.Tags = tags
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r agentSettingsResource) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	var plan AgentSettingsResource
+	ctx = r.provider.createAuthContext(ctx)
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tags := plan.Tags
+	if tags == nil {
+		agent, err := getAgentByID(ctx, *r.provider.client.AgentsApi, int32(plan.AgentID.Value))
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Error while getting agent %d", plan.AgentID.Value), err.Error())
+			return
+		}
+		tags = agentTagNames(agent.AgentTags)
+	}
+
+	_, err := r.provider.client.AgentsApi.V1NetworkAgentsUpdate(ctx, int32(plan.AgentID.Value)).V1NetworkAgentsUpdateRequest(syntropy.V1NetworkAgentsUpdateRequest{
+		AgentTags: tags,
+		AgentName: &plan.Name.Value,
+	}).Execute()
+	if err != nil {
+		resp.Diagnostics.AddError("Error while updating agent settings", err.Error())
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r agentSettingsResource) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	var data AgentSettingsResource
+	ctx = r.provider.createAuthContext(ctx)
+	diags := req.State.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.RestoreOnDestroy.Value || data.OriginalName.Null {
+		return
+	}
+
+	_, err := r.provider.client.AgentsApi.V1NetworkAgentsUpdate(ctx, int32(data.AgentID.Value)).V1NetworkAgentsUpdateRequest(syntropy.V1NetworkAgentsUpdateRequest{
+		AgentTags: data.OriginalTags,
+		AgentName: &data.OriginalName.Value,
+	}).Execute()
+	if err != nil {
+		resp.Diagnostics.AddError("Error while restoring original agent settings", err.Error())
+		return
+	}
+}
+
+func (r agentSettingsResource) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	agentID, err := strconv.ParseInt(req.ID, 10, 64)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Invalid agent ID %q", req.ID), err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), agentID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("agent_id"), agentID)...)
+}
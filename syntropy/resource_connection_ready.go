@@ -0,0 +1,223 @@
+package syntropy
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ tfsdk.ResourceType = connectionReadyResourceType{}
+var _ tfsdk.Resource = connectionReadyResource{}
+
+const (
+	connectionStatusConnected = "CONNECTED"
+	connectionStatusNotFound  = "NOT_FOUND"
+
+	defaultConnectionReadyTimeout = 10 * time.Minute
+)
+
+type connectionReadyResourceType struct{}
+
+type connectionReadyResource struct {
+	provider provider
+}
+
+func (t connectionReadyResourceType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		Description: "Waits until network connections are established. Resources that need working connections can depend on it",
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Description: "Connection ready ID randomly generated",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"connection_group_ids": {
+				Description: "IDs of network connections to wait for",
+				Required:    true,
+				Type: types.SetType{
+					ElemType: types.Int64Type,
+				},
+				Validators: []tfsdk.AttributeValidator{
+					setvalidator.SizeAtLeast(1),
+				},
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"timeout": {
+				Description: "How long to wait for connections to be established, e.g. \"5m\" or \"1h\". Defaults to 10m",
+				Type:        types.StringType,
+				Optional:    true,
+				Validators: []tfsdk.AttributeValidator{
+					durationValidator{},
+				},
+			},
+			"statuses": {
+				Description: "Map of connection group ID to connection status",
+				Computed:    true,
+				Type: types.MapType{
+					ElemType: types.StringType,
+				},
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+		},
+	}, nil
+}
+
+func (t connectionReadyResourceType) NewResource(ctx context.Context, in tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	provider, diags := convertProviderType(in)
+	return connectionReadyResource{
+		provider: provider,
+	}, diags
+}
+
+func (r connectionReadyResource) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	var plan ConnectionReadyResource
+	ctx = r.provider.createAuthContext(ctx)
+	diags := req.Config.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := defaultConnectionReadyTimeout
+	if !plan.Timeout.Null {
+		value, err := parseRelativeDuration(plan.Timeout.Value)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("timeout"), "Error while parsing timeout", err.Error())
+			return
+		}
+		timeout = value
+	}
+
+	var (
+		statuses map[string]string
+		notReady []string
+	)
+	err := pollWithBackoff(ctx, timeout, func() (bool, error) {
+		var (
+			reasons map[string]string
+			err     error
+		)
+		statuses, reasons, err = r.getStatuses(ctx, plan.ConnectionGroupIDs)
+		if err != nil {
+			return false, err
+		}
+		notReady = notReadyConnections(statuses, reasons)
+		return len(notReady) == 0, nil
+	})
+	switch {
+	case err == ErrWaitTimeout:
+		resp.Diagnostics.AddError(
+			"Timeout while waiting for network connections",
+			fmt.Sprintf("%d of %d connections are not connected after %s: %s", len(notReady), len(statuses), timeout, strings.Join(notReady, ", ")),
+		)
+		return
+	case err != nil && ctx.Err() != nil:
+		resp.Diagnostics.AddError("Waiting for network connections was cancelled", err.Error())
+		return
+	case err != nil:
+		resp.Diagnostics.AddError("Error while getting network connection status", err.Error())
+		return
+	}
+	plan.Statuses = statuses
+
+	plan.ID = types.String{Value: uuid.New().String()}
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r connectionReadyResource) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	var state ConnectionReadyResource
+	ctx = r.provider.createAuthContext(ctx)
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	statuses, _, err := r.getStatuses(ctx, state.ConnectionGroupIDs)
+	if err != nil {
+		resp.Diagnostics.AddError("Error while getting network connection status", err.Error())
+		return
+	}
+
+	state.Statuses = statuses
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r connectionReadyResource) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	var plan ConnectionReadyResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only timeout can be changed in place, it is used on the next create
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r connectionReadyResource) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	// Nothing to delete, connections are managed by other resources
+}
+
+// getStatuses returns status and status reason of each connection by connection group ID. Missing connections get
+// NOT_FOUND status
+func (r connectionReadyResource) getStatuses(ctx context.Context, connectionGroupIDs []int64) (map[string]string, map[string]string, error) {
+	connections, err := getConnectionGroupsByIDs(ctx, *r.provider.client.ConnectionsApi, int64ArrayToInt32Array(connectionGroupIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statuses := map[string]string{}
+	reasons := map[string]string{}
+	for _, id := range connectionGroupIDs {
+		statuses[fmt.Sprint(id)] = connectionStatusNotFound
+	}
+	for _, connection := range connections {
+		key := fmt.Sprint(connection.AgentConnectionGroupId)
+		if _, ok := statuses[key]; !ok {
+			continue
+		}
+		statuses[key] = string(connection.AgentConnectionGroupStatus)
+		reasons[key] = nullableStringToString(connection.AgentConnectionGroupStatusReason)
+	}
+	return statuses, reasons, nil
+}
+
+// notReadyConnections describes connections that are not connected yet in stable order
+func notReadyConnections(statuses, reasons map[string]string) []string {
+	var out []string
+	for id, status := range statuses {
+		if status == connectionStatusConnected {
+			continue
+		}
+		if reasons[id] != "" {
+			status = fmt.Sprintf("%s (%s)", status, reasons[id])
+		}
+		out = append(out, fmt.Sprintf("%s: %s", id, status))
+	}
+	sort.Strings(out)
+	return out
+}
@@ -2,19 +2,36 @@ package syntropy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/SyntropyNet/syntropy-sdk-go/syntropy"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/schemavalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces
 var _ tfsdk.ResourceType = networkConnectionResourceType{}
 var _ tfsdk.Resource = networkConnectionResource{}
 var _ tfsdk.ResourceWithImportState = networkConnectionResource{}
+var _ tfsdk.ResourceWithModifyPlan = networkConnectionResource{}
+
+const (
+	connectionAgentSide1 = "agent_1"
+	connectionAgentSide2 = "agent_2"
+
+	defaultServicesWaitTimeout = 10 * time.Minute
+)
 
 type networkConnectionResourceType struct{}
 
@@ -35,15 +52,54 @@ func (t networkConnectionResourceType) GetSchema(ctx context.Context) (tfsdk.Sch
 				},
 			},
 			"agent_peer": {
-				Description: "List of agent IDs for network connection",
+				Description: "List of agent IDs for network connection. Exactly one of agent_peer, agent_peer_names or agent_1_id and agent_2_id must be set",
 				Type: types.SetType{
 					ElemType: types.Int64Type,
 				},
-				Required: true,
+				Optional: true,
+				Computed: true,
+				Validators: []tfsdk.AttributeValidator{
+					setvalidator.SizeBetween(2, 2),
+					schemavalidator.ExactlyOneOf(path.MatchRoot("agent_1_id"), path.MatchRoot("agent_peer_names")),
+				},
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"agent_peer_names": {
+				Description: "Names of agents for network connection. Names are resolved to agent IDs stored in agent_peer by exact match",
+				Type: types.SetType{
+					ElemType: types.StringType,
+				},
+				Optional: true,
 				Validators: []tfsdk.AttributeValidator{
 					setvalidator.SizeBetween(2, 2),
 				},
+			},
+			"agent_1_id": {
+				Description: "ID of agent 1 of the connection. Can be set together with agent_2_id instead of agent_peer to control connection direction. Configured order is kept even if platform reports agents in reverse order",
+				Type:        types.Int64Type,
+				Optional:    true,
+				Computed:    true,
+				Validators: []tfsdk.AttributeValidator{
+					schemavalidator.AlsoRequires(path.MatchRoot("agent_2_id")),
+				},
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+					tfsdk.RequiresReplace(),
+				},
+			},
+			"agent_2_id": {
+				Description: "ID of agent 2 of the connection. Can be set together with agent_1_id instead of agent_peer to control connection direction. Configured order is kept even if platform reports agents in reverse order",
+				Type:        types.Int64Type,
+				Optional:    true,
+				Computed:    true,
+				Validators: []tfsdk.AttributeValidator{
+					schemavalidator.AlsoRequires(path.MatchRoot("agent_1_id")),
+				},
 				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
 					tfsdk.RequiresReplace(),
 				},
 			},
@@ -52,6 +108,97 @@ func (t networkConnectionResourceType) GetSchema(ctx context.Context) (tfsdk.Sch
 				Type:        types.BoolType,
 				Optional:    true,
 			},
+			"adopt_existing": {
+				Description: "Takes over existing connection between the same agents instead of failing to create a new one",
+				Type:        types.BoolType,
+				Optional:    true,
+			},
+			"status": {
+				Description: "Current status of the connection",
+				Type:        types.StringType,
+				Computed:    true,
+			},
+			"status_reason": {
+				Description: "Reason of the current connection status, if it is not connected",
+				Type:        types.StringType,
+				Computed:    true,
+			},
+			"latency_ms": {
+				Description: "Connection latency in milliseconds",
+				Type:        types.Float64Type,
+				Computed:    true,
+			},
+			"packet_loss": {
+				Description: "Connection packet loss in percent",
+				Type:        types.Float64Type,
+				Computed:    true,
+			},
+			"sdn_active": {
+				Description: "Is traffic currently routed through SDN? False means traffic goes directly between agents",
+				Type:        types.BoolType,
+				Computed:    true,
+			},
+			"wait_for_services": {
+				Description: "Waits after connection is created until agents report expected services. At least one of min_count or names must be set",
+				Optional:    true,
+				Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+					"min_count": {
+						Description: "Minimum number of connection services to wait for. At least one of min_count or names must be set",
+						Type:        types.Int64Type,
+						Optional:    true,
+						Validators: []tfsdk.AttributeValidator{
+							int64validator.AtLeast(1),
+							schemavalidator.AtLeastOneOf(path.MatchRelative().AtParent().AtName("names")),
+						},
+					},
+					"names": {
+						Description: "Names of connection services to wait for",
+						Optional:    true,
+						Type: types.SetType{
+							ElemType: types.StringType,
+						},
+					},
+					"timeout": {
+						Description: "How long to wait for services, e.g. \"5m\" or \"1h\". Defaults to 10m",
+						Type:        types.StringType,
+						Optional:    true,
+						Validators: []tfsdk.AttributeValidator{
+							durationValidator{},
+						},
+					},
+				}),
+			},
+			"enable_services": {
+				Description: "Enables connection services matching all set selectors after connection is created and on every update. At least one selector must be set. Services that don't match are left untouched",
+				Optional:    true,
+				Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+					"name_regex": {
+						Description: "Regular expression service name must match",
+						Type:        types.StringType,
+						Optional:    true,
+						Validators: []tfsdk.AttributeValidator{
+							regexValidator{},
+							schemavalidator.AtLeastOneOf(
+								path.MatchRelative().AtParent().AtName("type"),
+								path.MatchRelative().AtParent().AtName("agent_side"),
+							),
+						},
+					},
+					"type": {
+						Description: "Service type (Kubernetes, Docker, etc.)",
+						Type:        types.StringType,
+						Optional:    true,
+					},
+					"agent_side": {
+						Description: "Agent of the connection that publishes services. Possible values: agent_1, agent_2. If not set, services of both agents are matched",
+						Type:        types.StringType,
+						Optional:    true,
+						Validators: []tfsdk.AttributeValidator{
+							stringvalidator.OneOf(connectionAgentSide1, connectionAgentSide2),
+						},
+					},
+				}),
+			},
 			"services": {
 				Description: "List of services inside in network connection",
 				Computed:    true,
@@ -108,11 +255,20 @@ func (r networkConnectionResource) Create(ctx context.Context, req tfsdk.CreateR
 		return
 	}
 
+	// Agent names are already resolved by ModifyPlan, so resolved IDs are taken from plan instead of searching again
+	if plan.AgentPeerNames != nil {
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("agent_peer"), &plan.AgentIds)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	agent1ID, agent2ID := connectionAgentIDs(plan)
 	connection, _, err := r.provider.client.ConnectionsApi.V1NetworkConnectionsCreateP2P(ctx).V1NetworkConnectionsCreateP2PRequest(syntropy.V1NetworkConnectionsCreateP2PRequest{
 		AgentPairs: []syntropy.V1NetworkConnectionsCreateP2PRequestAgentPairsInner{
 			{
-				Agent2Id: int32(plan.AgentIds[0]),
-				Agent1Id: int32(plan.AgentIds[1]),
+				Agent1Id: agent1ID,
+				Agent2Id: agent2ID,
 			},
 		},
 		SdnEnabled: &plan.SdnEnabled.Value,
@@ -122,17 +278,57 @@ func (r networkConnectionResource) Create(ctx context.Context, req tfsdk.CreateR
 		return
 	}
 
-	if len(connection.Data) == 0 {
-		resp.Diagnostics.AddError(fmt.Sprintf("Something went wrong while creating connection between agent_1_id=%d, agent_2_id=%d", plan.AgentIds[0], plan.AgentIds[1]), "Import network connection to state if connection already exists")
-		return
+	var connectionGroupID int32
+	if len(connection.Data) > 0 {
+		connectionGroupID = *connection.Data[0].AgentConnectionGroupId
+	} else {
+		if !plan.AdoptExisting.Value {
+			resp.Diagnostics.AddError(fmt.Sprintf("Something went wrong while creating connection between agent_1_id=%d, agent_2_id=%d", agent1ID, agent2ID), "Import network connection to state or set adopt_existing if connection already exists")
+			return
+		}
+
+		existing, err := r.adoptConnection(ctx, agent1ID, agent2ID, plan.SdnEnabled.Value)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Error while adopting existing connection between agent_1_id=%d, agent_2_id=%d", agent1ID, agent2ID), err.Error())
+			return
+		}
+		connectionGroupID = existing.AgentConnectionGroupId
 	}
 
-	connectionDetails, err := getOneConnectionDetails(ctx, *r.provider.client.ConnectionsApi, *connection.Data[0].AgentConnectionGroupId)
+	connectionDetails, err := getOneConnectionDetails(ctx, *r.provider.client.ConnectionsApi, connectionGroupID)
 	if err != nil {
-		resp.Diagnostics.AddError(fmt.Sprintf("Unable to get connection %d services", *connection.Data[0].AgentConnectionGroupId), err.Error())
+		resp.Diagnostics.AddError(fmt.Sprintf("Unable to get connection %d services", connectionGroupID), err.Error())
 		return
 	}
-	plan.ID = types.Int64{Value: int64(*connection.Data[0].AgentConnectionGroupId)}
+	// Connection is saved to state even if services don't appear or can't be enabled, so it is not orphaned
+	if plan.WaitForServices != nil {
+		discovered, err := r.waitForServices(ctx, connectionGroupID, *plan.WaitForServices)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Error while waiting for connection %d services", connectionGroupID), err.Error())
+		} else {
+			connectionDetails = discovered
+		}
+	}
+	if plan.EnableServices != nil && !resp.Diagnostics.HasError() {
+		enabled, err := r.enableServices(ctx, *connectionDetails, *plan.EnableServices)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Error while enabling connection %d services", connectionGroupID), err.Error())
+		} else {
+			connectionDetails = enabled
+		}
+	}
+
+	// Status is refreshed on next read if it can't be fetched now
+	connectionGroup, err := getConnectionGroupByID(ctx, *r.provider.client.ConnectionsApi, connectionGroupID)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Unable to get connection %d status", connectionGroupID), err.Error())
+		clearConnectionStatus(&plan)
+	} else {
+		setConnectionStatus(&plan, *connectionGroup)
+	}
+
+	plan.ID = types.Int64{Value: int64(connectionGroupID)}
+	setConnectionAgents(&plan, connectionDetails.Agent1ID, connectionDetails.Agent2ID)
 	plan.Services = connectionDetails.Services
 	diags = resp.State.Set(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -148,7 +344,7 @@ func (r networkConnectionResource) Read(ctx context.Context, req tfsdk.ReadResou
 		return
 	}
 
-	connection, err := r.getConnectionGroupByAgentIDs(ctx, int32(state.AgentIds[0]), int32(state.AgentIds[1]))
+	connection, err := r.getConnectionGroup(ctx, state)
 	if err != nil {
 		if err == ErrConnectionNotFound {
 			resp.State.RemoveResource(ctx)
@@ -164,8 +360,7 @@ func (r networkConnectionResource) Read(ctx context.Context, req tfsdk.ReadResou
 		return
 	}
 
-	state.Services = connectionDetails.Services
-	state.SdnEnabled = types.Bool{Value: connection.AgentConnectionGroupSdnEnabled}
+	setConnectionState(&state, *connection, *connectionDetails)
 	diags = resp.State.Set(ctx, &state)
 
 	resp.Diagnostics.Append(diags...)
@@ -202,7 +397,22 @@ func (r networkConnectionResource) Update(ctx context.Context, req tfsdk.UpdateR
 		return
 	}
 
+	if plan.EnableServices != nil {
+		connectionDetails, err = r.enableServices(ctx, *connectionDetails, *plan.EnableServices)
+		if err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Error while enabling connection %d services", plan.ID.Value), err.Error())
+			return
+		}
+	}
+
+	connection, err := getConnectionGroupByID(ctx, *r.provider.client.ConnectionsApi, int32(plan.ID.Value))
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Unable to get connection %d status", plan.ID.Value), err.Error())
+		return
+	}
+
 	plan.Services = connectionDetails.Services
+	setConnectionStatus(&plan, *connection)
 
 	diags = resp.State.Set(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -226,20 +436,312 @@ func (r networkConnectionResource) Delete(ctx context.Context, req tfsdk.DeleteR
 	}
 }
 
+// ModifyPlan resolves agent_peer_names to agent IDs, so plan shows which agents will be connected
+func (r networkConnectionResource) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	ctx = r.provider.createAuthContext(ctx)
+	agentIDs, unknown, diags := resolveConfigAgentNames(ctx, *r.provider.client.AgentsApi, req.Config, path.Root("agent_peer_names"))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Names known only after apply may resolve to other agents, so IDs from state can't be kept in plan
+	if unknown {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("agent_peer"), types.Set{Unknown: true, ElemType: types.Int64Type})...)
+		if !req.State.Raw.IsNull() {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("agent_peer"))
+		}
+		return
+	}
+	if agentIDs == nil {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("agent_peer"), agentIDs)...)
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	// Name may now point to another agent, e.g. if agent was recreated, so connection has to be recreated as well.
+	// Renamed agent with the same ID is updated in place
+	var stateAgentIDs []int64
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("agent_peer"), &stateAgentIDs)...)
+	if !int64SetsEqual(stateAgentIDs, agentIDs) {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("agent_peer"))
+	}
+}
+
 func (r networkConnectionResource) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
-	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	ctx = r.provider.createAuthContext(ctx)
+	clt := *r.provider.client.ConnectionsApi
+
+	var (
+		connection *syntropy.V1Connection
+		err        error
+	)
+	if agents := strings.Split(req.ID, ":"); len(agents) == 2 {
+		agentID1, err1 := strconv.ParseInt(agents[0], 10, 32)
+		agentID2, err2 := strconv.ParseInt(agents[1], 10, 32)
+		if err1 != nil || err2 != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Invalid network connection import ID %q", req.ID), "Expected connection group ID or agent IDs in format agent_1_id:agent_2_id")
+			return
+		}
+		connection, err = getConnectionGroupByAgentPair(ctx, clt, int32(agentID1), int32(agentID2))
+	} else {
+		connectionGroupID, parseErr := strconv.ParseInt(req.ID, 10, 32)
+		if parseErr != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Invalid network connection import ID %q", req.ID), "Expected connection group ID or agent IDs in format agent_1_id:agent_2_id")
+			return
+		}
+		connection, err = getConnectionGroupByID(ctx, clt, int32(connectionGroupID))
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Unable to find network connection %q", req.ID), err.Error())
+		return
+	}
+
+	connectionDetails, err := getOneConnectionDetails(ctx, clt, connection.AgentConnectionGroupId)
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Unable to get connection %d services", connection.AgentConnectionGroupId), err.Error())
+		return
+	}
+
+	// Attributes that are only used on create are left null, so the first plan after import doesn't show changes
+	state := NetworkConnection{
+		AdoptExisting: types.Bool{Null: true},
+	}
+	setConnectionState(&state, *connection, *connectionDetails)
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// adoptConnection looks up existing connection between planned agents and reconciles its SDN setting with plan
+func (r networkConnectionResource) adoptConnection(ctx context.Context, agent1ID, agent2ID int32, sdnEnabled bool) (*syntropy.V1Connection, error) {
+	connection, err := getConnectionGroupByAgentPair(ctx, *r.provider.client.ConnectionsApi, agent1ID, agent2ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if connection.AgentConnectionGroupSdnEnabled != sdnEnabled {
+		_, err = r.provider.client.ConnectionsApi.V1NetworkConnectionsUpdate(ctx).V1NetworkConnectionsUpdateRequest(syntropy.V1NetworkConnectionsUpdateRequest{
+			Changes: []syntropy.V1ConnectionUpdateChange{
+				{
+					ConnectionGroupId: connection.AgentConnectionGroupId,
+					IsSdnEnabled:      sdnEnabled,
+				},
+			},
+		}).Execute()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return connection, nil
+}
+
+// getConnectionGroup reads connection group by stored ID. If group with that ID is gone, connection between the same
+// agents is looked up, as it may have been recreated outside terraform
+func (r networkConnectionResource) getConnectionGroup(ctx context.Context, state NetworkConnection) (*syntropy.V1Connection, error) {
+	clt := *r.provider.client.ConnectionsApi
+	if !state.ID.Null && !state.ID.Unknown {
+		connection, err := getConnectionGroupByID(ctx, clt, int32(state.ID.Value))
+		if err != ErrConnectionNotFound {
+			return connection, err
+		}
+	}
+
+	if len(state.AgentIds) != 2 && state.Agent1ID.Null {
+		return nil, ErrConnectionNotFound
+	}
+	agent1ID, agent2ID := connectionAgentIDs(state)
+	return getConnectionGroupByAgentPair(ctx, clt, agent1ID, agent2ID)
+}
+
+// waitForServices polls connection services until expected services are discovered or timeout is reached
+func (r networkConnectionResource) waitForServices(ctx context.Context, connectionGroupID int32, wait ConnectionServicesWait) (*Connection, error) {
+	timeout := defaultServicesWaitTimeout
+	if !wait.Timeout.Null {
+		value, err := parseRelativeDuration(wait.Timeout.Value)
+		if err != nil {
+			return nil, err
+		}
+		timeout = value
+	}
+
+	var (
+		connection *Connection
+		missing    []string
+	)
+	err := pollWithBackoff(ctx, timeout, func() (bool, error) {
+		var err error
+		connection, err = getOneConnectionDetails(ctx, *r.provider.client.ConnectionsApi, connectionGroupID)
+		if err != nil {
+			return false, err
+		}
+		missing = missingConnectionServices(connection.Services, wait.Names)
+		return len(connection.Services) >= int(wait.MinCount.Value) && len(missing) == 0, nil
+	})
+	if err == ErrWaitTimeout {
+		msg := fmt.Sprintf("found %d services after %s", len(connection.Services), timeout)
+		if !wait.MinCount.Null {
+			msg += fmt.Sprintf(", expected at least %d", wait.MinCount.Value)
+		}
+		if len(missing) > 0 {
+			msg += fmt.Sprintf(", missing services: %s", strings.Join(missing, ", "))
+		}
+		return nil, errors.New(msg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return connection, nil
+}
+
+// missingConnectionServices returns names that none of services have
+func missingConnectionServices(services []ConnectionServiceData, names []string) []string {
+	found := map[string]bool{}
+	for _, service := range services {
+		found[service.Name] = true
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
 }
 
-func (r networkConnectionResource) getConnectionGroupByAgentIDs(ctx context.Context, agentID1 int32, agentID2 int32) (*syntropy.V1Connection, error) {
-	resp, _, err := r.provider.client.ConnectionsApi.V1NetworkConnectionsGet(ctx).Execute()
+// enableServices enables connection services matching selector. Services that don't match are left untouched.
+// Connection with refreshed services is returned
+func (r networkConnectionResource) enableServices(ctx context.Context, connection Connection, selector ConnectionServiceSelector) (*Connection, error) {
+	services, err := selectConnectionServices(connection, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []syntropy.AgentServicesUpdateChanges
+	for _, service := range services {
+		if service.Enabled {
+			continue
+		}
+		changes = append(changes, syntropy.AgentServicesUpdateChanges{
+			AgentServiceSubnetId: int32(service.ID),
+			IsEnabled:            true,
+		})
+	}
+
+	if len(changes) == 0 {
+		return &connection, nil
+	}
+
+	_, err = r.provider.client.ConnectionsApi.V1NetworkConnectionsServicesUpdate(ctx).V1NetworkConnectionsServicesUpdateRequest(syntropy.V1NetworkConnectionsServicesUpdateRequest{
+		AgentConnectionGroupId: &connection.ConnectionGroupID,
+		Changes:                changes,
+	}).Execute()
 	if err != nil {
 		return nil, err
 	}
+	return getOneConnectionDetails(ctx, *r.provider.client.ConnectionsApi, connection.ConnectionGroupID)
+}
+
+// selectConnectionServices returns connection services matching all set selector fields. Selector without any fields
+// is rejected, so a misconfigured selector never enables all services
+func selectConnectionServices(connection Connection, selector ConnectionServiceSelector) ([]ConnectionServiceData, error) {
+	if selector.NameRegex.Null && selector.Type.Null && selector.AgentSide.Null {
+		return nil, errors.New("at least one of name_regex, type or agent_side must be set")
+	}
+
+	var nameRegex *regexp.Regexp
+	if !selector.NameRegex.Null && !selector.NameRegex.Unknown {
+		compiled, err := regexp.Compile(selector.NameRegex.Value)
+		if err != nil {
+			return nil, err
+		}
+		nameRegex = compiled
+	}
 
-	for _, group := range resp.Data {
-		if (group.Agent1.AgentId == agentID1 && group.Agent2.AgentId == agentID2) || (group.Agent1.AgentId == agentID2 && group.Agent2.AgentId == agentID1) {
-			return &group, nil
+	var agentID *int64
+	switch selector.AgentSide.Value {
+	case connectionAgentSide1:
+		id := int64(connection.Agent1ID)
+		agentID = &id
+	case connectionAgentSide2:
+		id := int64(connection.Agent2ID)
+		agentID = &id
+	}
+
+	var out []ConnectionServiceData
+	for _, service := range connection.Services {
+		if nameRegex != nil && !nameRegex.MatchString(service.Name) {
+			continue
+		}
+		if !selector.Type.Null && selector.Type.Value != service.Type {
+			continue
 		}
+		if agentID != nil && *agentID != service.AgentID {
+			continue
+		}
+		out = append(out, service)
+	}
+	return out, nil
+}
+
+// setConnectionState copies connection group and its services reported by platform to resource data
+func setConnectionState(data *NetworkConnection, connection syntropy.V1Connection, details Connection) {
+	data.ID = types.Int64{Value: int64(connection.AgentConnectionGroupId)}
+	setConnectionAgents(data, connection.Agent1.AgentId, connection.Agent2.AgentId)
+	data.SdnEnabled = types.Bool{Value: connection.AgentConnectionGroupSdnEnabled}
+	data.Services = details.Services
+	setConnectionStatus(data, connection)
+}
+
+// clearConnectionStatus sets connection health attributes to null when platform status is not available
+func clearConnectionStatus(data *NetworkConnection) {
+	data.Status = types.String{Null: true}
+	data.StatusReason = types.String{Null: true}
+	data.SdnActive = types.Bool{Null: true}
+	data.LatencyMs = types.Float64{Null: true}
+	data.PacketLoss = types.Float64{Null: true}
+}
+
+// setConnectionStatus copies connection health reported by platform to resource data
+func setConnectionStatus(data *NetworkConnection, connection syntropy.V1Connection) {
+	data.Status = types.String{Value: string(connection.AgentConnectionGroupStatus)}
+	data.StatusReason = types.String{Value: nullableStringToString(connection.AgentConnectionGroupStatusReason)}
+	data.SdnActive = types.Bool{Value: connection.AgentConnectionGroupSdnActive}
+	data.LatencyMs = types.Float64{Null: true}
+	if connection.AgentConnectionLatencyMs != nil {
+		data.LatencyMs = types.Float64{Value: float64(*connection.AgentConnectionLatencyMs)}
+	}
+	data.PacketLoss = types.Float64{Null: true}
+	if connection.AgentConnectionPacketLoss != nil {
+		data.PacketLoss = types.Float64{Value: float64(*connection.AgentConnectionPacketLoss)}
+	}
+}
+
+// setConnectionAgents stores agents of connection reported by platform. Platform may report agents in different order
+// than they were configured, so agent_1_id and agent_2_id already in data are kept if they are the same pair
+func setConnectionAgents(data *NetworkConnection, agent1ID, agent2ID int32) {
+	if data.Agent1ID.Null || data.Agent1ID.Unknown || data.Agent2ID.Null || data.Agent2ID.Unknown ||
+		agentPairKey(data.Agent1ID.Value, data.Agent2ID.Value) != agentPairKey(int64(agent1ID), int64(agent2ID)) {
+		data.Agent1ID = types.Int64{Value: int64(agent1ID)}
+		data.Agent2ID = types.Int64{Value: int64(agent2ID)}
+	}
+	data.AgentIds = []int64{data.Agent1ID.Value, data.Agent2ID.Value}
+}
+
+// connectionAgentIDs returns agents of connection. Explicit agent_1_id and agent_2_id take precedence over agent_peer
+func connectionAgentIDs(data NetworkConnection) (int32, int32) {
+	if !data.Agent1ID.Null && !data.Agent1ID.Unknown {
+		return int32(data.Agent1ID.Value), int32(data.Agent2ID.Value)
 	}
-	return nil, ErrConnectionNotFound
+	// agent_peer is a set, so it has no agent order. Its elements are sent in reverse, as resource always did before
+	// agent_1_id and agent_2_id were added, so connections keep the same direction as they had before the upgrade
+	return int32(data.AgentIds[1]), int32(data.AgentIds[0])
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/SyntropyNet/syntropy-sdk-go/syntropy"
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/schemavalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
@@ -15,6 +16,7 @@ import (
 var _ tfsdk.ResourceType = networkConnectionMeshResourceType{}
 var _ tfsdk.Resource = networkConnectionMeshResource{}
 var _ tfsdk.ResourceWithImportState = networkConnectionMeshResource{}
+var _ tfsdk.ResourceWithModifyPlan = networkConnectionMeshResource{}
 
 type networkConnectionMeshResourceType struct{}
 
@@ -35,14 +37,25 @@ func (t networkConnectionMeshResourceType) GetSchema(ctx context.Context) (tfsdk
 				},
 			},
 			"agent_ids": {
-				Description: "List of agent IDs for network connection mesh",
+				Description: "List of agent IDs for network connection mesh. Exactly one of agent_ids or agent_names must be set",
 				Type: types.SetType{
 					ElemType: types.NumberType,
 				},
 				PlanModifiers: tfsdk.AttributePlanModifiers{
 					tfsdk.UseStateForUnknown(),
 				},
-				Required: true,
+				Optional: true,
+				Computed: true,
+				Validators: []tfsdk.AttributeValidator{
+					schemavalidator.ExactlyOneOf(path.MatchRoot("agent_names")),
+				},
+			},
+			"agent_names": {
+				Description: "List of agent names for network connection mesh. Names are resolved to agent IDs stored in agent_ids by exact match",
+				Type: types.SetType{
+					ElemType: types.StringType,
+				},
+				Optional: true,
 			},
 			"sdn_enabled": {
 				Description: "Should SDN be enabled?",
@@ -129,6 +142,14 @@ func (r networkConnectionMeshResource) Create(ctx context.Context, req tfsdk.Cre
 		return
 	}
 
+	// Agent names are already resolved by ModifyPlan, so resolved IDs are taken from plan instead of searching again
+	if plan.AgentNames != nil {
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("agent_ids"), &plan.AgentIds)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	var agentList []syntropy.V1NetworkConnectionsCreateMeshRequestAgentIdsInner
 	for _, i := range plan.AgentIds {
 		agentList = append(agentList, syntropy.V1NetworkConnectionsCreateMeshRequestAgentIdsInner{
@@ -301,6 +322,7 @@ func (r networkConnectionMeshResource) Update(ctx context.Context, req tfsdk.Upd
 	newState := NetworkConnectionMesh{
 		ID:          plan.ID,
 		AgentIds:    plan.AgentIds,
+		AgentNames:  plan.AgentNames,
 		Connections: connections,
 		SdnEnabled:  plan.SdnEnabled,
 	}
@@ -403,6 +425,32 @@ func (r networkConnectionMeshResource) GetConnectionsListByAgentID(ctx context.C
 	return connections, nil
 }
 
+// ModifyPlan resolves agent_names to agent IDs, so plan shows which agents will be connected. Changed agents are
+// connected or disconnected by Update
+func (r networkConnectionMeshResource) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	ctx = r.provider.createAuthContext(ctx)
+	agentIDs, unknown, diags := resolveConfigAgentNames(ctx, *r.provider.client.AgentsApi, req.Config, path.Root("agent_names"))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Names known only after apply may resolve to other agents, so IDs from state can't be kept in plan
+	if unknown {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("agent_ids"), types.Set{Unknown: true, ElemType: types.NumberType})...)
+		return
+	}
+	if agentIDs == nil {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("agent_ids"), int64ArrayToInt32Array(agentIDs))...)
+}
+
 func (r networkConnectionMeshResource) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
 	tfsdk.ResourceImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
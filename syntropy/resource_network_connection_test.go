@@ -0,0 +1,161 @@
+package syntropy
+
+import (
+	"github.com/SyntropyNet/syntropy-sdk-go/syntropy"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"reflect"
+	"testing"
+)
+
+func testConnection(connectionGroupID, agent1ID, agent2ID int32) syntropy.V1Connection {
+	return syntropy.V1Connection{
+		AgentConnectionGroupId:         connectionGroupID,
+		AgentConnectionGroupSdnEnabled: true,
+		Agent1:                         syntropy.V1ConnectionAgent{AgentId: agent1ID},
+		Agent2:                         syntropy.V1ConnectionAgent{AgentId: agent2ID},
+	}
+}
+
+func TestSetConnectionState(t *testing.T) {
+	services := []ConnectionServiceData{{ID: 7, Name: "postgres", AgentID: 2, ConnectionId: 10}}
+	state := NetworkConnection{AdoptExisting: types.Bool{Null: true}}
+	setConnectionState(&state, testConnection(10, 1, 2), Connection{Agent1ID: 1, Agent2ID: 2, ConnectionGroupID: 10, Services: services})
+
+	if state.ID.Value != 10 {
+		t.Errorf("ID = %d, want 10", state.ID.Value)
+	}
+	if !int64SetsEqual(state.AgentIds, []int64{1, 2}) {
+		t.Errorf("AgentIds = %v, want [1 2]", state.AgentIds)
+	}
+	if !state.SdnEnabled.Value {
+		t.Error("SdnEnabled = false, want true")
+	}
+	if !reflect.DeepEqual(state.Services, services) {
+		t.Errorf("Services = %v, want %v", state.Services, services)
+	}
+
+	// Attributes that are only used on create must stay null, otherwise imported connection shows changes
+	if !state.AdoptExisting.Null {
+		t.Errorf("AdoptExisting = %v, want null", state.AdoptExisting)
+	}
+	if state.AgentPeerNames != nil || state.WaitForServices != nil || state.EnableServices != nil {
+		t.Errorf("create only attributes are set: agent_peer_names=%v, wait_for_services=%v, enable_services=%v", state.AgentPeerNames, state.WaitForServices, state.EnableServices)
+	}
+}
+
+func TestSetConnectionAgents(t *testing.T) {
+	tests := []struct {
+		name       string
+		agent1ID   types.Int64
+		agent2ID   types.Int64
+		reported1  int32
+		reported2  int32
+		wantAgent1 int64
+		wantAgent2 int64
+	}{
+		{"not configured", types.Int64{Null: true}, types.Int64{Null: true}, 1, 2, 1, 2},
+		{"unknown", types.Int64{Unknown: true}, types.Int64{Unknown: true}, 2, 1, 2, 1},
+		{"same order", types.Int64{Value: 1}, types.Int64{Value: 2}, 1, 2, 1, 2},
+		{"reversed by platform", types.Int64{Value: 2}, types.Int64{Value: 1}, 1, 2, 2, 1},
+		{"different pair", types.Int64{Value: 1}, types.Int64{Value: 3}, 1, 2, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := NetworkConnection{Agent1ID: tt.agent1ID, Agent2ID: tt.agent2ID}
+			setConnectionAgents(&data, tt.reported1, tt.reported2)
+			if data.Agent1ID.Value != tt.wantAgent1 || data.Agent2ID.Value != tt.wantAgent2 {
+				t.Errorf("agents = %d:%d, want %d:%d", data.Agent1ID.Value, data.Agent2ID.Value, tt.wantAgent1, tt.wantAgent2)
+			}
+			if data.Agent1ID.Null || data.Agent1ID.Unknown || data.Agent2ID.Null || data.Agent2ID.Unknown {
+				t.Errorf("agents are not known: %v, %v", data.Agent1ID, data.Agent2ID)
+			}
+			if !int64SetsEqual(data.AgentIds, []int64{tt.wantAgent1, tt.wantAgent2}) {
+				t.Errorf("AgentIds = %v, want [%d %d]", data.AgentIds, tt.wantAgent1, tt.wantAgent2)
+			}
+		})
+	}
+}
+
+func TestConnectionAgentIDs(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       NetworkConnection
+		wantAgent1 int32
+		wantAgent2 int32
+	}{
+		{"explicit agents", NetworkConnection{Agent1ID: types.Int64{Value: 1}, Agent2ID: types.Int64{Value: 2}, AgentIds: []int64{2, 1}}, 1, 2},
+		{"agent_peer", NetworkConnection{Agent1ID: types.Int64{Null: true}, Agent2ID: types.Int64{Null: true}, AgentIds: []int64{1, 2}}, 2, 1},
+		{"unknown explicit agents", NetworkConnection{Agent1ID: types.Int64{Unknown: true}, Agent2ID: types.Int64{Unknown: true}, AgentIds: []int64{3, 4}}, 4, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent1ID, agent2ID := connectionAgentIDs(tt.data)
+			if agent1ID != tt.wantAgent1 || agent2ID != tt.wantAgent2 {
+				t.Errorf("connectionAgentIDs() = %d, %d, want %d, %d", agent1ID, agent2ID, tt.wantAgent1, tt.wantAgent2)
+			}
+		})
+	}
+}
+
+func TestSelectConnectionServices(t *testing.T) {
+	connection := Connection{
+		Agent1ID: 1,
+		Agent2ID: 2,
+		Services: []ConnectionServiceData{
+			{ID: 10, Name: "postgres", Type: "DOCKER", AgentID: 1},
+			{ID: 11, Name: "postgres-replica", Type: "DOCKER", AgentID: 2},
+			{ID: 12, Name: "web", Type: "KUBERNETES", AgentID: 2},
+		},
+	}
+	null := types.String{Null: true}
+
+	tests := []struct {
+		name     string
+		selector ConnectionServiceSelector
+		want     []int64
+		wantErr  bool
+	}{
+		{"name_regex", ConnectionServiceSelector{NameRegex: types.String{Value: "^postgres"}, Type: null, AgentSide: null}, []int64{10, 11}, false},
+		{"type", ConnectionServiceSelector{NameRegex: null, Type: types.String{Value: "KUBERNETES"}, AgentSide: null}, []int64{12}, false},
+		{"agent_side", ConnectionServiceSelector{NameRegex: null, Type: null, AgentSide: types.String{Value: connectionAgentSide2}}, []int64{11, 12}, false},
+		{"all selectors", ConnectionServiceSelector{NameRegex: types.String{Value: "postgres"}, Type: types.String{Value: "DOCKER"}, AgentSide: types.String{Value: connectionAgentSide1}}, []int64{10}, false},
+		{"no match", ConnectionServiceSelector{NameRegex: types.String{Value: "^redis$"}, Type: null, AgentSide: null}, nil, false},
+		{"no selectors", ConnectionServiceSelector{NameRegex: null, Type: null, AgentSide: null}, nil, true},
+		{"invalid regex", ConnectionServiceSelector{NameRegex: types.String{Value: "postgres["}, Type: null, AgentSide: null}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			services, err := selectConnectionServices(connection, tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectConnectionServices() error = %v, want error %v", err, tt.wantErr)
+			}
+			var got []int64
+			for _, service := range services {
+				got = append(got, service.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectConnectionServices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingConnectionServices(t *testing.T) {
+	services := []ConnectionServiceData{{Name: "postgres"}, {Name: "redis"}}
+	tests := []struct {
+		name  string
+		names []string
+		want  []string
+	}{
+		{"no names", nil, nil},
+		{"all found", []string{"redis", "postgres"}, nil},
+		{"some missing", []string{"web", "postgres", "api"}, []string{"api", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingConnectionServices(services, tt.names); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingConnectionServices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package syntropy
+
+import (
+	"context"
+	"fmt"
+	"github.com/SyntropyNet/syntropy-sdk-go/syntropy"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"sort"
+	"strings"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ tfsdk.ResourceType = networkConnectionsResourceType{}
+var _ tfsdk.Resource = networkConnectionsResource{}
+
+// networkConnectionsBatchSize is maximum number of agent pairs created in a single call
+const networkConnectionsBatchSize = 100
+
+type networkConnectionsResourceType struct{}
+
+type networkConnectionsResource struct {
+	provider provider
+}
+
+func (t networkConnectionsResourceType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		Description: "Creates and manages a set of point to point connections between Syntropy Platform agents",
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Description: "Network connections ID randomly generated",
+				Type:        types.StringType,
+				Computed:    true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					tfsdk.UseStateForUnknown(),
+				},
+			},
+			"connections": {
+				Description: "Set of agent pairs to connect. Each pair must be listed once, regardless of agent order",
+				Required:    true,
+				Validators: []tfsdk.AttributeValidator{
+					setvalidator.SizeAtLeast(1),
+					uniqueAgentPairsValidator{},
+				},
+				Attributes: tfsdk.SetNestedAttributes(map[string]tfsdk.Attribute{
+					"agent_1_id": {
+						Description: "Agent 1 ID",
+						Type:        types.Int64Type,
+						Required:    true,
+					},
+					"agent_2_id": {
+						Description: "Agent 2 ID",
+						Type:        types.Int64Type,
+						Required:    true,
+					},
+					"sdn_enabled": {
+						Description: "Should SDN be enabled?",
+						Type:        types.BoolType,
+						Optional:    true,
+					},
+				}),
+			},
+			"connection_group_ids": {
+				Description: "Map of agent pair in format lower_agent_id:higher_agent_id to connection group ID",
+				Computed:    true,
+				Type: types.MapType{
+					ElemType: types.Int64Type,
+				},
+			},
+		},
+	}, nil
+}
+
+func (t networkConnectionsResourceType) NewResource(ctx context.Context, in tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	provider, diags := convertProviderType(in)
+	return networkConnectionsResource{
+		provider: provider,
+	}, diags
+}
+
+func (r networkConnectionsResource) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	var plan NetworkConnectionsResource
+	ctx = r.provider.createAuthContext(ctx)
+	diags := req.Config.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	groupIDs := map[string]int64{}
+	created, err := createAgentPairs(ctx, connectionsAgentPairsClient{*r.provider.client.ConnectionsApi}, plan.Connections, groupIDs)
+	if err != nil {
+		resp.Diagnostics.AddError("Error while creating network connections", err.Error())
+	}
+
+	plan.ID = types.String{Value: uuid.New().String()}
+	plan.Connections = created
+	plan.ConnectionGroupIDs = int64MapToTfValue(groupIDs)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r networkConnectionsResource) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	var state NetworkConnectionsResource
+	ctx = r.provider.createAuthContext(ctx)
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	groupIDs, diags := int64MapFromTfValue(ctx, state.ConnectionGroupIDs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var ids []int32
+	for _, id := range groupIDs {
+		ids = append(ids, int32(id))
+	}
+
+	remoteConnections := map[int32]syntropy.V1Connection{}
+	if len(ids) > 0 {
+		connections, err := getConnectionGroupsByIDs(ctx, *r.provider.client.ConnectionsApi, ids)
+		if err != nil {
+			resp.Diagnostics.AddError("Error while getting network connections", err.Error())
+			return
+		}
+		for _, connection := range connections {
+			remoteConnections[connection.AgentConnectionGroupId] = connection
+		}
+	}
+
+	// Pairs removed outside terraform are dropped from state, so they are created again on the next apply
+	var pairs []NetworkConnectionsPair
+	for _, pair := range state.Connections {
+		key := pair.key()
+		groupID, ok := groupIDs[key]
+		if !ok {
+			continue
+		}
+		connection, ok := remoteConnections[int32(groupID)]
+		if !ok {
+			delete(groupIDs, key)
+			continue
+		}
+		// Unset SDN setting is kept unset while SDN is disabled, so it doesn't produce a diff
+		if !pair.SdnEnabled.Null || connection.AgentConnectionGroupSdnEnabled {
+			pair.SdnEnabled = types.Bool{Value: connection.AgentConnectionGroupSdnEnabled}
+		}
+		pairs = append(pairs, pair)
+	}
+
+	if len(pairs) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Connections = pairs
+	state.ConnectionGroupIDs = int64MapToTfValue(groupIDs)
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r networkConnectionsResource) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	var plan, state NetworkConnectionsResource
+	ctx = r.provider.createAuthContext(ctx)
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	groupIDs, diags := int64MapFromTfValue(ctx, state.ConnectionGroupIDs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planPairs := map[string]NetworkConnectionsPair{}
+	for _, pair := range plan.Connections {
+		planPairs[pair.key()] = pair
+	}
+
+	// Remove pairs that are not in plan anymore in a single call and update SDN of changed pairs in another one
+	var (
+		removeRequest = syntropy.V1NetworkConnectionsRemoveRequest{}
+		updateRequest = syntropy.V1NetworkConnectionsUpdateRequest{}
+		pairs         []NetworkConnectionsPair
+		existing      = map[string]bool{}
+	)
+	for _, pair := range state.Connections {
+		key := pair.key()
+		groupID, ok := groupIDs[key]
+		if !ok {
+			// Pair without known connection group is treated as missing, so it is created again if still planned
+			continue
+		}
+		planPair, ok := planPairs[key]
+		if !ok {
+			removeRequest.AgentConnectionGroupIds = append(removeRequest.AgentConnectionGroupIds, int32(groupID))
+			delete(groupIDs, key)
+			continue
+		}
+		if planPair.SdnEnabled.Value != pair.SdnEnabled.Value {
+			updateRequest.Changes = append(updateRequest.Changes, syntropy.V1ConnectionUpdateChange{
+				ConnectionGroupId: int32(groupID),
+				IsSdnEnabled:      planPair.SdnEnabled.Value,
+			})
+		}
+		existing[key] = true
+		pairs = append(pairs, planPair)
+	}
+
+	if len(removeRequest.AgentConnectionGroupIds) > 0 {
+		_, err := r.provider.client.ConnectionsApi.V1NetworkConnectionsRemove(ctx).V1NetworkConnectionsRemoveRequest(removeRequest).Execute()
+		if err != nil {
+			resp.Diagnostics.AddError("Error while deleting network connections", err.Error())
+			return
+		}
+	}
+
+	if len(updateRequest.Changes) > 0 {
+		_, err := r.provider.client.ConnectionsApi.V1NetworkConnectionsUpdate(ctx).V1NetworkConnectionsUpdateRequest(updateRequest).Execute()
+		if err != nil {
+			resp.Diagnostics.AddError("Error while updating network connections", err.Error())
+			return
+		}
+	}
+
+	var added []NetworkConnectionsPair
+	for _, pair := range plan.Connections {
+		if !existing[pair.key()] {
+			added = append(added, pair)
+		}
+	}
+
+	created, err := createAgentPairs(ctx, connectionsAgentPairsClient{*r.provider.client.ConnectionsApi}, added, groupIDs)
+	if err != nil {
+		resp.Diagnostics.AddError("Error while creating network connections", err.Error())
+	}
+
+	plan.Connections = append(pairs, created...)
+	plan.ConnectionGroupIDs = int64MapToTfValue(groupIDs)
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r networkConnectionsResource) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	var data NetworkConnectionsResource
+	ctx = r.provider.createAuthContext(ctx)
+	diags := req.State.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	groupIDs, diags := int64MapFromTfValue(ctx, data.ConnectionGroupIDs)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	removeRequest := syntropy.V1NetworkConnectionsRemoveRequest{}
+	for _, id := range groupIDs {
+		removeRequest.AgentConnectionGroupIds = append(removeRequest.AgentConnectionGroupIds, int32(id))
+	}
+
+	if len(removeRequest.AgentConnectionGroupIds) == 0 {
+		return
+	}
+
+	_, err := r.provider.client.ConnectionsApi.V1NetworkConnectionsRemove(ctx).V1NetworkConnectionsRemoveRequest(removeRequest).Execute()
+	if err != nil {
+		resp.Diagnostics.AddError("Error while deleting network connections", err.Error())
+		return
+	}
+}
+
+// agentPairsClient is part of connections API used to create agent pairs
+type agentPairsClient interface {
+	createP2P(ctx context.Context, request syntropy.V1NetworkConnectionsCreateP2PRequest) (*syntropy.V1NetworkConnectionsCreateP2PResponse, error)
+	searchConnections(ctx context.Context, filter syntropy.V1ConnectionFilter) ([]syntropy.V1Connection, error)
+}
+
+type connectionsAgentPairsClient struct {
+	clt syntropy.ConnectionsApiService
+}
+
+func (c connectionsAgentPairsClient) createP2P(ctx context.Context, request syntropy.V1NetworkConnectionsCreateP2PRequest) (*syntropy.V1NetworkConnectionsCreateP2PResponse, error) {
+	resp, _, err := c.clt.V1NetworkConnectionsCreateP2P(ctx).V1NetworkConnectionsCreateP2PRequest(request).Execute()
+	return resp, err
+}
+
+func (c connectionsAgentPairsClient) searchConnections(ctx context.Context, filter syntropy.V1ConnectionFilter) ([]syntropy.V1Connection, error) {
+	return searchAllConnections(ctx, c.clt, filter)
+}
+
+// createAgentPairs creates agent pairs in batches, one call per SDN setting and batch. Connection group IDs of created
+// pairs are added to groupIDs. Pairs that were created are returned even if a later batch fails, so callers save them to
+// state and they are not duplicated on the next apply
+func createAgentPairs(ctx context.Context, clt agentPairsClient, pairs []NetworkConnectionsPair, groupIDs map[string]int64) ([]NetworkConnectionsPair, error) {
+	var created []NetworkConnectionsPair
+	for _, batch := range batchAgentPairs(pairs, networkConnectionsBatchSize) {
+		request := syntropy.V1NetworkConnectionsCreateP2PRequest{
+			SdnEnabled: &batch[0].SdnEnabled.Value,
+		}
+		for _, pair := range batch {
+			request.AgentPairs = append(request.AgentPairs, syntropy.V1NetworkConnectionsCreateP2PRequestAgentPairsInner{
+				Agent1Id: int32(pair.Agent1ID),
+				Agent2Id: int32(pair.Agent2ID),
+			})
+		}
+
+		resp, err := clt.createP2P(ctx, request)
+		if err != nil {
+			return created, err
+		}
+
+		// Create response only lists connection group IDs, so pairs are matched by searching created groups by agents.
+		// Groups that existed before are not in the response and are not taken over
+		createdGroups := map[int32]bool{}
+		for _, data := range resp.Data {
+			if data.AgentConnectionGroupId != nil {
+				createdGroups[*data.AgentConnectionGroupId] = true
+			}
+		}
+		var connections []syntropy.V1Connection
+		if len(createdGroups) > 0 {
+			found, err := clt.searchConnections(ctx, syntropy.V1ConnectionFilter{
+				AgentPair: agentPairFilters(batch),
+			})
+			if err != nil {
+				return created, err
+			}
+			for _, connection := range found {
+				if createdGroups[connection.AgentConnectionGroupId] {
+					connections = append(connections, connection)
+				}
+			}
+		}
+		batchIDs := connectionGroupIDsByPair(connections)
+
+		var missing []string
+		for _, pair := range batch {
+			id, ok := batchIDs[pair.key()]
+			if !ok {
+				missing = append(missing, pair.key())
+				continue
+			}
+			groupIDs[pair.key()] = id
+			created = append(created, pair)
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return created, fmt.Errorf("connections between agents %s were not created. Remove them from platform if they already exist", strings.Join(missing, ", "))
+		}
+	}
+	return created, nil
+}
+
+// batchAgentPairs splits pairs into batches of at most size pairs sharing the same SDN setting. Pairs without SDN come first
+func batchAgentPairs(pairs []NetworkConnectionsPair, size int) [][]NetworkConnectionsPair {
+	bySdn := map[bool][]NetworkConnectionsPair{}
+	for _, pair := range pairs {
+		bySdn[pair.SdnEnabled.Value] = append(bySdn[pair.SdnEnabled.Value], pair)
+	}
+
+	var batches [][]NetworkConnectionsPair
+	for _, sdnEnabled := range []bool{false, true} {
+		group := bySdn[sdnEnabled]
+		for start := 0; start < len(group); start += size {
+			end := start + size
+			if end > len(group) {
+				end = len(group)
+			}
+			batches = append(batches, group[start:end])
+		}
+	}
+	return batches
+}
+
+// agentPairFilters returns connection search filter matching given pairs in either agent order
+func agentPairFilters(pairs []NetworkConnectionsPair) []syntropy.V1AgentPairFilter {
+	var filters []syntropy.V1AgentPairFilter
+	for _, pair := range pairs {
+		filters = append(filters,
+			syntropy.V1AgentPairFilter{Agent1Id: int32(pair.Agent1ID), Agent2Id: int32(pair.Agent2ID)},
+			syntropy.V1AgentPairFilter{Agent1Id: int32(pair.Agent2ID), Agent2Id: int32(pair.Agent1ID)},
+		)
+	}
+	return filters
+}
+
+// connectionGroupIDsByPair returns connection group IDs keyed by agent pair key
+func connectionGroupIDsByPair(connections []syntropy.V1Connection) map[string]int64 {
+	ids := map[string]int64{}
+	for _, connection := range connections {
+		ids[agentPairKey(int64(connection.Agent1.AgentId), int64(connection.Agent2.AgentId))] = int64(connection.AgentConnectionGroupId)
+	}
+	return ids
+}
+
+// key returns pair key that doesn't depend on agent order, so reversed pair refers to the same connection
+func (p NetworkConnectionsPair) key() string {
+	return agentPairKey(p.Agent1ID, p.Agent2ID)
+}
@@ -0,0 +1,171 @@
+package syntropy
+
+import (
+	"context"
+	"errors"
+	"github.com/SyntropyNet/syntropy-sdk-go/syntropy"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"reflect"
+	"testing"
+)
+
+func testPairs(sdnEnabled bool, keys ...[2]int64) []NetworkConnectionsPair {
+	var pairs []NetworkConnectionsPair
+	for _, key := range keys {
+		pairs = append(pairs, NetworkConnectionsPair{Agent1ID: key[0], Agent2ID: key[1], SdnEnabled: types.Bool{Value: sdnEnabled}})
+	}
+	return pairs
+}
+
+func TestNetworkConnectionsPairKey(t *testing.T) {
+	tests := []struct {
+		pair NetworkConnectionsPair
+		want string
+	}{
+		{NetworkConnectionsPair{Agent1ID: 1, Agent2ID: 2}, "1:2"},
+		{NetworkConnectionsPair{Agent1ID: 2, Agent2ID: 1}, "1:2"},
+		{NetworkConnectionsPair{Agent1ID: 12, Agent2ID: 3}, "3:12"},
+	}
+	for _, tt := range tests {
+		if got := tt.pair.key(); got != tt.want {
+			t.Errorf("key() of %d:%d = %s, want %s", tt.pair.Agent1ID, tt.pair.Agent2ID, got, tt.want)
+		}
+	}
+}
+
+func TestBatchAgentPairs(t *testing.T) {
+	sdn := testPairs(true, [2]int64{1, 2}, [2]int64{1, 3})
+	plain := testPairs(false, [2]int64{2, 3}, [2]int64{2, 4}, [2]int64{2, 5})
+
+	tests := []struct {
+		name  string
+		pairs []NetworkConnectionsPair
+		size  int
+		want  [][]NetworkConnectionsPair
+	}{
+		{"empty", nil, 2, nil},
+		{"single batch", plain, 5, [][]NetworkConnectionsPair{plain}},
+		{"split by size", plain, 2, [][]NetworkConnectionsPair{plain[:2], plain[2:]}},
+		{"split by sdn", append(append([]NetworkConnectionsPair{}, sdn...), plain...), 5, [][]NetworkConnectionsPair{plain, sdn}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := batchAgentPairs(tt.pairs, tt.size); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("batchAgentPairs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentPairFilters(t *testing.T) {
+	got := agentPairFilters(testPairs(false, [2]int64{1, 2}, [2]int64{4, 3}))
+	want := []syntropy.V1AgentPairFilter{
+		{Agent1Id: 1, Agent2Id: 2},
+		{Agent1Id: 2, Agent2Id: 1},
+		{Agent1Id: 4, Agent2Id: 3},
+		{Agent1Id: 3, Agent2Id: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("agentPairFilters() = %v, want %v", got, want)
+	}
+}
+
+func TestConnectionGroupIDsByPair(t *testing.T) {
+	got := connectionGroupIDsByPair([]syntropy.V1Connection{testConnection(10, 1, 2), testConnection(11, 4, 3)})
+	want := map[string]int64{"1:2": 10, "3:4": 11}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("connectionGroupIDsByPair() = %v, want %v", got, want)
+	}
+}
+
+// fakeAgentPairsClient creates connection groups in memory. Create calls after failAfter successful ones fail and
+// pairs listed in existing are not created, as platform skips pairs that are already connected
+type fakeAgentPairsClient struct {
+	failAfter   int
+	existing    map[string]bool
+	calls       int
+	nextGroupID int32
+	connections []syntropy.V1Connection
+}
+
+func (c *fakeAgentPairsClient) createP2P(_ context.Context, request syntropy.V1NetworkConnectionsCreateP2PRequest) (*syntropy.V1NetworkConnectionsCreateP2PResponse, error) {
+	c.calls++
+	if c.calls > c.failAfter {
+		return nil, errors.New("platform error")
+	}
+
+	resp := &syntropy.V1NetworkConnectionsCreateP2PResponse{}
+	for _, pair := range request.AgentPairs {
+		if c.existing[agentPairKey(int64(pair.Agent1Id), int64(pair.Agent2Id))] {
+			continue
+		}
+		c.nextGroupID++
+		groupID := c.nextGroupID
+		c.connections = append(c.connections, testConnection(groupID, pair.Agent1Id, pair.Agent2Id))
+		resp.Data = append(resp.Data, syntropy.V1NetworkConnectionsCreateP2PResponseDataInner{AgentConnectionGroupId: &groupID})
+	}
+	return resp, nil
+}
+
+func (c *fakeAgentPairsClient) searchConnections(_ context.Context, filter syntropy.V1ConnectionFilter) ([]syntropy.V1Connection, error) {
+	var found []syntropy.V1Connection
+	for _, connection := range c.connections {
+		for _, pair := range filter.AgentPair {
+			if connection.Agent1.AgentId == pair.Agent1Id && connection.Agent2.AgentId == pair.Agent2Id {
+				found = append(found, connection)
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+func TestCreateAgentPairs(t *testing.T) {
+	plain := testPairs(false, [2]int64{1, 2}, [2]int64{3, 1})
+	sdn := testPairs(true, [2]int64{2, 3})
+	pairs := append(append([]NetworkConnectionsPair{}, plain...), sdn...)
+
+	tests := []struct {
+		name         string
+		client       *fakeAgentPairsClient
+		wantCreated  []NetworkConnectionsPair
+		wantGroupIDs map[string]int64
+		wantErr      bool
+	}{
+		{
+			name:         "all batches created",
+			client:       &fakeAgentPairsClient{failAfter: 2},
+			wantCreated:  pairs,
+			wantGroupIDs: map[string]int64{"1:2": 1, "1:3": 2, "2:3": 3},
+		},
+		{
+			name:         "second batch fails",
+			client:       &fakeAgentPairsClient{failAfter: 1},
+			wantCreated:  plain,
+			wantGroupIDs: map[string]int64{"1:2": 1, "1:3": 2},
+			wantErr:      true,
+		},
+		{
+			name:         "existing pair is not taken over",
+			client:       &fakeAgentPairsClient{failAfter: 2, existing: map[string]bool{"1:3": true}},
+			wantCreated:  plain[:1],
+			wantGroupIDs: map[string]int64{"1:2": 1},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groupIDs := map[string]int64{}
+			created, err := createAgentPairs(context.Background(), tt.client, pairs, groupIDs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("createAgentPairs() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(created, tt.wantCreated) {
+				t.Errorf("created pairs = %v, want %v", created, tt.wantCreated)
+			}
+			if !reflect.DeepEqual(groupIDs, tt.wantGroupIDs) {
+				t.Errorf("group IDs = %v, want %v", groupIDs, tt.wantGroupIDs)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package syntropy
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var _ tfsdk.AttributeValidator = dateExpressionValidator{}
+
+// dateExpressionValidator validates that string attribute is RFC3339 timestamp or relative duration
+type dateExpressionValidator struct{}
+
+func (v dateExpressionValidator) Description(_ context.Context) string {
+	return `value must be RFC3339 timestamp (e.g. "2022-07-01T00:00:00Z") or relative duration (e.g. "-24h", "+1h", "7d ago")`
+}
+
+func (v dateExpressionValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v dateExpressionValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var value types.String
+	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &value)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || value.Null || value.Unknown {
+		return
+	}
+
+	if _, err := parseDateExpression(value.Value, time.Now()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.AttributePath, "Invalid date", v.Description(ctx)+": "+err.Error())
+	}
+}
+
+var _ tfsdk.AttributeValidator = regexValidator{}
+
+// regexValidator validates that string attribute is valid regular expression
+type regexValidator struct{}
+
+func (v regexValidator) Description(_ context.Context) string {
+	return "value must be valid regular expression"
+}
+
+func (v regexValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v regexValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var value types.String
+	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &value)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || value.Null || value.Unknown {
+		return
+	}
+
+	if _, err := regexp.Compile(value.Value); err != nil {
+		resp.Diagnostics.AddAttributeError(req.AttributePath, "Invalid regular expression", err.Error())
+	}
+}
+
+var _ tfsdk.AttributeValidator = cidrValidator{}
+
+// cidrValidator validates that string attribute is valid CIDR notation IP network
+type cidrValidator struct{}
+
+func (v cidrValidator) Description(_ context.Context) string {
+	return `value must be IP network in CIDR notation, e.g. "203.0.113.0/24"`
+}
+
+func (v cidrValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v cidrValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var value types.String
+	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &value)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || value.Null || value.Unknown {
+		return
+	}
+
+	if _, _, err := net.ParseCIDR(value.Value); err != nil {
+		resp.Diagnostics.AddAttributeError(req.AttributePath, "Invalid CIDR", v.Description(ctx)+": "+err.Error())
+	}
+}
+
+var _ tfsdk.AttributeValidator = durationValidator{}
+
+// durationValidator validates that string attribute is unsigned duration, with days (d) and weeks (w) supported
+type durationValidator struct{}
+
+func (v durationValidator) Description(_ context.Context) string {
+	return `value must be duration, e.g. "30m", "12h" or "7d"`
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var value types.String
+	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &value)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || value.Null || value.Unknown {
+		return
+	}
+
+	if _, err := parseRelativeDuration(value.Value); err != nil {
+		resp.Diagnostics.AddAttributeError(req.AttributePath, "Invalid duration", v.Description(ctx)+": "+err.Error())
+	}
+}
+
+var _ tfsdk.AttributeValidator = uniqueAgentPairsValidator{}
+
+// uniqueAgentPairsValidator validates that set of agent pairs has no pair listed twice, in either agent order
+type uniqueAgentPairsValidator struct{}
+
+func (v uniqueAgentPairsValidator) Description(_ context.Context) string {
+	return "each agent pair must be listed once, regardless of agent order"
+}
+
+func (v uniqueAgentPairsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v uniqueAgentPairsValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var value types.Set
+	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &value)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || value.Null || value.Unknown {
+		return
+	}
+
+	var pairs []struct {
+		Agent1ID   types.Int64 `tfsdk:"agent_1_id"`
+		Agent2ID   types.Int64 `tfsdk:"agent_2_id"`
+		SdnEnabled types.Bool  `tfsdk:"sdn_enabled"`
+	}
+	diags = value.ElementsAs(ctx, &pairs, false)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+
+	var keys []string
+	for _, pair := range pairs {
+		// Pairs with agent IDs known only after apply can't be compared yet
+		if pair.Agent1ID.Null || pair.Agent1ID.Unknown || pair.Agent2ID.Null || pair.Agent2ID.Unknown {
+			continue
+		}
+		keys = append(keys, agentPairKey(pair.Agent1ID.Value, pair.Agent2ID.Value))
+	}
+
+	if duplicates := duplicateStrings(keys); len(duplicates) > 0 {
+		resp.Diagnostics.AddAttributeError(req.AttributePath, "Duplicate agent pairs", v.Description(ctx)+": "+strings.Join(duplicates, ", "))
+	}
+}